@@ -0,0 +1,668 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+
+	gofile "./writeGoFile"
+)
+
+// export site found by call graph analysis
+type exportSite struct {
+	path        string
+	serviceName string
+	busType     string
+	position    token.Position
+	// end of export call, it is invalid if call can not be mapped to syntax
+	end token.Position
+	// position of dbusutil.Service.Export call, it differs from position if export is
+	// in helper func
+	call token.Position
+}
+
+// get positions of export calls which implementers are resolved by call graph analysis
+func resolvedExportCalls(sites map[string][]*exportSite) map[token.Position]bool {
+	calls := make(map[token.Position]bool)
+	for _, typeSites := range sites {
+		for _, site := range typeSites {
+			calls[site.call] = true
+		}
+	}
+	return calls
+}
+
+// key of exported type, type from different load is matched by package path and name
+func exportTypeKey(obj types.Object) string {
+	if obj == nil || obj.Pkg() == nil {
+		return ""
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// field is identified by struct type and index, so stores and loads in different functions match
+type fieldKey struct {
+	structType string
+	index      int
+}
+
+// find concrete types which flow into dbusutil.Service.Export, across package boundaries
+type exportAnalysis struct {
+	prog *ssa.Program
+	// call graph built by variable type analysis
+	cg *callgraph.Graph
+	// values stored to field, global or local address
+	stores map[interface{}][]ssa.Value
+	// values stored to elements of array or slice
+	elemStores map[ssa.Value][]ssa.Value
+	// closures created of anonymous function
+	closures map[*ssa.Function][]*ssa.MakeClosure
+	// packages by path, use to map call site to syntax
+	pkgs map[string]*packages.Package
+	// service tracer of each package
+	tracers map[string]*gofile.ServiceTracer
+	// names requested by RequestName of service values
+	requestNames map[ssa.Value][]string
+}
+
+// max depth of callers which params of export call are traced to in context
+const maxContextDepth = 4
+
+// call which binds params of callee, params of callee are traced to args of it only
+type boundCall struct {
+	callee *ssa.Function
+	site   ssa.CallInstruction
+}
+
+// trace values in context of calls, from function of export call to its callers, so each
+// caller of a helper func which wraps export gets its own path, implementers and service
+type valueTrace struct {
+	*exportAnalysis
+	calls []*boundCall
+	// function which params are not bound yet, its callers are needed if they are traced
+	frontier *ssa.Function
+	// params of frontier are traced, context should be extended by callers
+	open bool
+}
+
+// find export sites of every exported type in packages loaded by loadPackages
+func AnalyzeExports(pkgs []*packages.Package) map[string][]*exportSite {
+	// ssa can only be built from well typed packages, exports in other packages are still found
+	var wellTyped []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg.IllTyped {
+			gofile.Reportf(nil, gofile.SeverityWarning, gofile.CodeNoCallGraph,
+				"%s or its dependencies have errors, exports of it are found in the same package only",
+				pkg.PkgPath)
+			continue
+		}
+		wellTyped = append(wellTyped, pkg)
+	}
+	if len(wellTyped) == 0 {
+		return nil
+	}
+
+	prog, _ := ssautil.AllPackages(wellTyped, ssa.InstantiateGenerics)
+	prog.Build()
+	funcs := ssautil.AllFunctions(prog)
+
+	a := &exportAnalysis{
+		prog:         prog,
+		cg:           vta.CallGraph(funcs, cha.CallGraph(prog)),
+		stores:       make(map[interface{}][]ssa.Value),
+		elemStores:   make(map[ssa.Value][]ssa.Value),
+		closures:     make(map[*ssa.Function][]*ssa.MakeClosure),
+		pkgs:         make(map[string]*packages.Package),
+		tracers:      make(map[string]*gofile.ServiceTracer),
+		requestNames: make(map[ssa.Value][]string),
+	}
+	packages.Visit(wellTyped, nil, func(pkg *packages.Package) {
+		a.pkgs[pkg.PkgPath] = pkg
+	})
+	a.indexInstructions(funcs)
+	a.indexRequestNames(funcs)
+	return a.findExportSites(funcs)
+}
+
+// record stores and closures of all functions
+func (a *exportAnalysis) indexInstructions(funcs map[*ssa.Function]bool) {
+	for fn := range funcs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Store:
+					if key := a.addrKey(instr.Addr); key != nil {
+						a.stores[key] = append(a.stores[key], instr.Val)
+					}
+					if indexAddr, ok := instr.Addr.(*ssa.IndexAddr); ok {
+						a.elemStores[indexAddr.X] = append(a.elemStores[indexAddr.X], instr.Val)
+					}
+				case *ssa.MakeClosure:
+					if closureFn, ok := instr.Fn.(*ssa.Function); ok {
+						a.closures[closureFn] = append(a.closures[closureFn], instr)
+					}
+				}
+			}
+		}
+	}
+}
+
+// get key of address, nil if address is not tracked
+func (a *exportAnalysis) addrKey(addr ssa.Value) interface{} {
+	switch addr := addr.(type) {
+	case *ssa.FieldAddr:
+		pointer, ok := addr.X.Type().Underlying().(*types.Pointer)
+		if !ok {
+			return nil
+		}
+		return fieldKey{structType: types.TypeString(pointer.Elem(), nil), index: addr.Field}
+	case *ssa.Global:
+		return addr
+	case *ssa.Alloc:
+		return addr
+	}
+	return nil
+}
+
+// record names requested by services, service is identified by values it is traced to
+func (a *exportAnalysis) indexRequestNames(funcs map[*ssa.Function]bool) {
+	t := a.trace(nil, nil)
+	for fn := range funcs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				call, ok := instr.(ssa.CallInstruction)
+				if !ok || !isServiceMethod(call.Common().StaticCallee(), "RequestName") ||
+					len(call.Common().Args) != 2 {
+					continue
+				}
+				args := call.Common().Args
+				names := t.constStrings(args[1])
+				if len(names) != 1 {
+					continue
+				}
+				for _, src := range t.sources(args[0], make(map[ssa.Value]bool), make(map[ssa.Value]bool)) {
+					a.requestNames[src] = append(a.requestNames[src], names[0])
+				}
+			}
+		}
+	}
+}
+
+// create trace in context of calls, params of frontier are not traced to callers
+func (a *exportAnalysis) trace(calls []*boundCall, frontier *ssa.Function) *valueTrace {
+	return &valueTrace{exportAnalysis: a, calls: calls, frontier: frontier}
+}
+
+// find calls of dbusutil.Service.Export
+func (a *exportAnalysis) findExportSites(funcs map[*ssa.Function]bool) map[string][]*exportSite {
+	sites := make(map[string][]*exportSite)
+	for fn := range funcs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				call, ok := instr.(ssa.CallInstruction)
+				// args are service, path and implementers
+				if !ok || !isServiceMethod(call.Common().StaticCallee(), "Export") ||
+					len(call.Common().Args) != 3 {
+					continue
+				}
+				for key, keySites := range a.exportSitesOf(call) {
+					sites[key] = append(sites[key], keySites...)
+				}
+			}
+		}
+	}
+	// functions are iterated in random order, sort sites by position
+	for _, typeSites := range sites {
+		sort.SliceStable(typeSites, func(i, j int) bool {
+			pi, pj := typeSites[i].position, typeSites[j].position
+			if pi.Filename != pj.Filename {
+				return pi.Filename < pj.Filename
+			}
+			if pi.Offset != pj.Offset {
+				return pi.Offset < pj.Offset
+			}
+			return typeSites[i].path < typeSites[j].path
+		})
+	}
+	return sites
+}
+
+// get export sites of export call, call is traced in context of its callers if path,
+// implementers or service of it come from params
+func (a *exportAnalysis) exportSitesOf(call ssa.CallInstruction) map[string][]*exportSite {
+	sites := make(map[string][]*exportSite)
+	queue := [][]*boundCall{nil}
+	for len(queue) > 0 {
+		calls := queue[0]
+		queue = queue[1:]
+		frontier := call.Parent()
+		if len(calls) > 0 {
+			frontier = calls[len(calls)-1].site.Parent()
+		}
+		// callers are not traced in context any more, params are traced to all callers
+		if len(calls) >= maxContextDepth {
+			frontier = nil
+		}
+		t := a.trace(calls, frontier)
+		args := call.Common().Args
+		paths := t.constStrings(args[1])
+		implementerTypes := t.implementerTypes(args[2])
+		service := t.service(args[0])
+		if t.open {
+			for _, edge := range a.cg.Nodes[frontier].In {
+				extended := append(append([]*boundCall(nil), calls...), &boundCall{callee: frontier, site: edge.Site})
+				queue = append(queue, extended)
+			}
+			continue
+		}
+
+		// site is the outermost call, path and implementers come from it
+		site := call
+		if len(calls) > 0 {
+			site = calls[len(calls)-1].site
+		}
+		position := a.prog.Fset.Position(site.Pos())
+		pkg, callExpr := a.getCallExpr(site.Parent(), site.Pos())
+		if callExpr != nil {
+			position = pkg.Fset.Position(callExpr.Pos())
+		}
+		exportPosition := a.prog.Fset.Position(call.Pos())
+		exportPkg, exportExpr := a.getCallExpr(call.Parent(), call.Common().Pos())
+		if exportExpr != nil {
+			exportPosition = exportPkg.Fset.Position(exportExpr.Pos())
+		}
+		if exportExpr != nil && (service.ServiceName == "" || service.BusType == "") {
+			// service which can not be traced in ssa, e.g. field of struct literal
+			if traced := a.getService(exportPkg, exportExpr); traced != nil {
+				if service.ServiceName == "" {
+					service.ServiceName = traced.ServiceName
+				}
+				if service.BusType == "" {
+					service.BusType = traced.BusType
+				}
+			}
+		}
+		path := ""
+		if len(paths) == 1 {
+			path = paths[0]
+		} else if exportExpr != nil && len(exportExpr.Args) > 0 {
+			// path is built at runtime, keep it as template
+			path = gofile.GetPathTemplate(exportPkg.TypesInfo, exportExpr.Args[0])
+		}
+		for _, ty := range implementerTypes {
+			named := getNamed(ty)
+			if named == nil {
+				continue
+			}
+			exportSite := &exportSite{
+				path:        path,
+				serviceName: service.ServiceName,
+				busType:     service.BusType,
+				position:    position,
+				call:        exportPosition,
+			}
+			if callExpr != nil {
+				exportSite.end = pkg.Fset.Position(callExpr.End())
+			}
+			key := exportTypeKey(named.Obj())
+			sites[key] = append(sites[key], exportSite)
+		}
+	}
+	return sites
+}
+
+// check if function is method of dbusutil.Service
+func isServiceMethod(fn *ssa.Function, name string) bool {
+	if fn == nil || fn.Name() != name || fn.Signature.Recv() == nil {
+		return false
+	}
+	named := getNamed(fn.Signature.Recv().Type())
+	if named == nil || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == "Service" && named.Obj().Pkg().Path() == gofile.DBusUtilPath
+}
+
+// get named type, pointer is dereferenced
+func getNamed(ty types.Type) *types.Named {
+	if pointer, ok := ty.(*types.Pointer); ok {
+		ty = pointer.Elem()
+	}
+	named, _ := ty.(*types.Named)
+	return named
+}
+
+// get concrete types of implementers slice, slice and its elements are traced with
+// their own visited values, so a value reached by both is traced for both
+func (t *valueTrace) implementerTypes(slice ssa.Value) []types.Type {
+	var result []types.Type
+	sliceVisited := make(map[ssa.Value]bool)
+	elemVisited := make(map[ssa.Value]bool)
+	for _, elem := range t.elements(slice, sliceVisited) {
+		for _, src := range t.sources(elem, elemVisited, sliceVisited) {
+			switch src := src.(type) {
+			case *ssa.MakeInterface:
+				result = append(result, src.X.Type())
+			default:
+				if !types.IsInterface(src.Type()) {
+					result = append(result, src.Type())
+				}
+			}
+		}
+	}
+	return result
+}
+
+// get values stored to elements of slice
+func (t *valueTrace) elements(slice ssa.Value, visited map[ssa.Value]bool) []ssa.Value {
+	var result []ssa.Value
+	for _, src := range t.sources(slice, visited, visited) {
+		// elements of appended slice come from both args
+		if call, ok := src.(*ssa.Call); ok {
+			if builtin, ok := call.Common().Value.(*ssa.Builtin); ok && builtin.Name() == "append" {
+				for _, arg := range call.Common().Args {
+					result = append(result, t.elements(arg, visited)...)
+				}
+			}
+			continue
+		}
+		base := src
+		if sliceValue, ok := src.(*ssa.Slice); ok {
+			base = sliceValue.X
+		}
+		result = append(result, t.elemStores[base]...)
+	}
+	return result
+}
+
+// get constant string values which expression may hold
+func (t *valueTrace) constStrings(value ssa.Value) []string {
+	var result []string
+	for _, src := range t.sources(value, make(map[ssa.Value]bool), make(map[ssa.Value]bool)) {
+		c, ok := src.(*ssa.Const)
+		if !ok || c.Value == nil || c.Value.Kind() != constant.String {
+			continue
+		}
+		result = append(result, constant.StringVal(c.Value))
+	}
+	return result
+}
+
+// trace value back to the values it is produced from, slices which value is loaded from
+// are traced with sliceVisited
+func (t *valueTrace) sources(value ssa.Value, visited map[ssa.Value]bool,
+	sliceVisited map[ssa.Value]bool) []ssa.Value {
+	if visited[value] {
+		return nil
+	}
+	visited[value] = true
+
+	var result []ssa.Value
+	switch v := value.(type) {
+	case *ssa.Phi:
+		for _, edge := range v.Edges {
+			result = append(result, t.sources(edge, visited, sliceVisited)...)
+		}
+	case *ssa.ChangeInterface:
+		result = t.sources(v.X, visited, sliceVisited)
+	case *ssa.ChangeType:
+		result = t.sources(v.X, visited, sliceVisited)
+	case *ssa.MakeInterface:
+		// interface made from interface, trace the inner one
+		if types.IsInterface(v.X.Type()) {
+			result = t.sources(v.X, visited, sliceVisited)
+		} else {
+			result = []ssa.Value{v}
+		}
+	case *ssa.TypeAssert:
+		if types.IsInterface(v.AssertedType) {
+			result = t.sources(v.X, visited, sliceVisited)
+		} else {
+			result = []ssa.Value{v}
+		}
+	case *ssa.Parameter:
+		result = t.paramSources(v, visited, sliceVisited)
+	case *ssa.FreeVar:
+		result = t.freeVarSources(v, visited, sliceVisited)
+	case *ssa.Call:
+		result = t.returnSources(v, 0, visited, sliceVisited)
+	case *ssa.Extract:
+		if call, ok := v.Tuple.(*ssa.Call); ok {
+			result = t.returnSources(call, v.Index, visited, sliceVisited)
+		}
+	case *ssa.UnOp:
+		if v.Op != token.MUL {
+			return []ssa.Value{v}
+		}
+		// load from element of slice or array
+		if indexAddr, ok := v.X.(*ssa.IndexAddr); ok {
+			for _, elem := range t.elements(indexAddr.X, sliceVisited) {
+				result = append(result, t.sources(elem, visited, sliceVisited)...)
+			}
+			return result
+		}
+		// load from address
+		key := t.addrKey(v.X)
+		if key == nil {
+			return []ssa.Value{v}
+		}
+		for _, stored := range t.stores[key] {
+			result = append(result, t.sources(stored, visited, sliceVisited)...)
+		}
+	default:
+		result = []ssa.Value{v}
+	}
+	return result
+}
+
+// trace parameter to args of every caller
+func (t *valueTrace) paramSources(param *ssa.Parameter, visited map[ssa.Value]bool,
+	sliceVisited map[ssa.Value]bool) []ssa.Value {
+	fn := param.Parent()
+	index := -1
+	for pIndex, p := range fn.Params {
+		if p == param {
+			index = pIndex
+		}
+	}
+	node := t.cg.Nodes[fn]
+	if index < 0 || node == nil {
+		return nil
+	}
+	// param is bound by call in context
+	for _, call := range t.calls {
+		if call.callee == fn {
+			if arg := getCallArg(call.site, index); arg != nil {
+				return t.sources(arg, visited, sliceVisited)
+			}
+			return nil
+		}
+	}
+	if fn == t.frontier && len(node.In) > 0 {
+		t.open = true
+		return nil
+	}
+	var result []ssa.Value
+	for _, edge := range node.In {
+		if arg := getCallArg(edge.Site, index); arg != nil {
+			result = append(result, t.sources(arg, visited, sliceVisited)...)
+		}
+	}
+	return result
+}
+
+// get arg of call for param at index, nil if there is not
+func getCallArg(site ssa.CallInstruction, index int) ssa.Value {
+	common := site.Common()
+	if common.IsInvoke() {
+		// receiver of interface call is not in args
+		if index == 0 {
+			return common.Value
+		}
+		index--
+	}
+	if index < len(common.Args) {
+		return common.Args[index]
+	}
+	return nil
+}
+
+// trace free var to bindings of closures
+func (t *valueTrace) freeVarSources(freeVar *ssa.FreeVar, visited map[ssa.Value]bool,
+	sliceVisited map[ssa.Value]bool) []ssa.Value {
+	fn := freeVar.Parent()
+	index := -1
+	for fIndex, fv := range fn.FreeVars {
+		if fv == freeVar {
+			index = fIndex
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+	var result []ssa.Value
+	for _, closure := range t.closures[fn] {
+		if index < len(closure.Bindings) {
+			binding := closure.Bindings[index]
+			// captured variable is an address, trace values stored to it
+			if alloc, ok := binding.(*ssa.Alloc); ok {
+				for _, stored := range t.stores[alloc] {
+					result = append(result, t.sources(stored, visited, sliceVisited)...)
+				}
+				continue
+			}
+			result = append(result, t.sources(binding, visited, sliceVisited)...)
+		}
+	}
+	return result
+}
+
+// trace call result to values returned by callees, call of external function is kept as source
+func (t *valueTrace) returnSources(call *ssa.Call, index int, visited map[ssa.Value]bool,
+	sliceVisited map[ssa.Value]bool) []ssa.Value {
+	var callees []*ssa.Function
+	if callee := call.Common().StaticCallee(); callee != nil {
+		callees = append(callees, callee)
+	} else if node := t.cg.Nodes[call.Parent()]; node != nil {
+		for _, edge := range node.Out {
+			if edge.Site == ssa.CallInstruction(call) {
+				callees = append(callees, edge.Callee.Func)
+			}
+		}
+	}
+
+	var result []ssa.Value
+	for _, callee := range callees {
+		// service or connection created by the call is what service is traced to
+		if len(callee.Blocks) == 0 || isBusCreator(callee) {
+			result = append(result, call)
+			continue
+		}
+		for _, block := range callee.Blocks {
+			for _, instr := range block.Instrs {
+				ret, ok := instr.(*ssa.Return)
+				if !ok || index >= len(ret.Results) {
+					continue
+				}
+				result = append(result, t.sources(ret.Results[index], visited, sliceVisited)...)
+			}
+		}
+	}
+	if len(callees) == 0 {
+		result = append(result, call)
+	}
+	return result
+}
+
+// check if function creates service or connection on certain bus
+func isBusCreator(fn *ssa.Function) bool {
+	return gofile.GetCreatorBusType(fn.Name()) != "" || fn.Name() == "NewService"
+}
+
+// get service which value holds, service name is from RequestName of values service is
+// traced to, bus type is from functions which create it
+func (t *valueTrace) service(value ssa.Value) *gofile.DBusService {
+	service := &gofile.DBusService{}
+	for _, src := range t.sources(value, make(map[ssa.Value]bool), make(map[ssa.Value]bool)) {
+		if names := t.requestNames[src]; len(names) > 0 && service.ServiceName == "" {
+			service.ServiceName = names[0]
+		}
+		if service.BusType == "" {
+			service.BusType = t.busType(src, make(map[ssa.Value]bool))
+		}
+	}
+	return service
+}
+
+// get bus type of service or connection created by call
+func (t *valueTrace) busType(src ssa.Value, visited map[ssa.Value]bool) string {
+	call, ok := src.(*ssa.Call)
+	if !ok || visited[src] {
+		return ""
+	}
+	visited[src] = true
+	callee := call.Common().StaticCallee()
+	if callee == nil {
+		return ""
+	}
+	if busType := gofile.GetCreatorBusType(callee.Name()); busType != "" {
+		return busType
+	}
+	// dbusutil.NewService(conn), service is on the bus of conn
+	if callee.Name() == "NewService" && len(call.Common().Args) == 1 {
+		for _, connSrc := range t.sources(call.Common().Args[0], make(map[ssa.Value]bool),
+			make(map[ssa.Value]bool)) {
+			if busType := t.busType(connSrc, visited); busType != "" {
+				return busType
+			}
+		}
+	}
+	return ""
+}
+
+// get syntax of export call by mapping call site position
+func (a *exportAnalysis) getCallExpr(fn *ssa.Function, lparen token.Pos) (*packages.Package, *ast.CallExpr) {
+	if fn.Pkg == nil {
+		return nil, nil
+	}
+	pkg := a.pkgs[fn.Pkg.Pkg.Path()]
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil, nil
+	}
+	var result *ast.CallExpr
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(node ast.Node) bool {
+			callExpr, ok := node.(*ast.CallExpr)
+			if !ok || callExpr.Lparen != lparen {
+				return result == nil
+			}
+			result = callExpr
+			return false
+		})
+	}
+	return pkg, result
+}
+
+// get service of export call
+func (a *exportAnalysis) getService(pkg *packages.Package, callExpr *ast.CallExpr) *gofile.DBusService {
+	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	tracer, ok := a.tracers[pkg.PkgPath]
+	if !ok {
+		tracer = gofile.NewServiceTracer(pkg.TypesInfo)
+		tracer.Trace(pkg.Syntax)
+		a.tracers[pkg.PkgPath] = tracer
+	}
+	return tracer.GetService(selector.X)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+
+	C "gopkg.in/check.v1"
+
+	gofile "./writeGoFile"
+)
+
+type analysisSuite struct{}
+
+var _ = C.Suite(&analysisSuite{})
+
+func (*analysisSuite) TestAnalyzeExports(c *C.C) {
+	gofile.TakeDiagnostics()
+	pkgs, err := loadPackages(filepath.Join("testdata", "exports"), []string{"./..."})
+	c.Assert(err, C.IsNil)
+	sites := AnalyzeExports(pkgs)
+
+	paths := make(map[string][]string)
+	for key, typeSites := range sites {
+		for _, site := range typeSites {
+			paths[key] = append(paths[key], site.path)
+		}
+	}
+	// exports through helper func, interface value and slice, export of fake service is not one,
+	// each call of helper func has its own path
+	c.Check(paths, C.DeepEquals, map[string][]string{
+		"example.com/exports.Manager": {"/com/deepin/Test/Manager"},
+		"example.com/exports.Device":  {"/com/deepin/Test/Device"},
+		"example.com/exports.User":    {"/com/deepin/Test/User"},
+		"example.com/exports.Group":   {"/com/deepin/Test/Group"},
+		"example.com/exports.Session": {"/com/deepin/Test/Group"},
+	})
+	// service is traced across packages, helper func gets it from callers
+	for key, typeSites := range sites {
+		c.Check(typeSites[0].serviceName, C.Equals, "com.deepin.Test", C.Commentf("%s", key))
+		c.Check(typeSites[0].busType, C.Equals, gofile.SessionBus, C.Commentf("%s", key))
+	}
+	// export through helper func is at call of helper func
+	c.Check(sites["example.com/exports.Device"][0].position.Filename, C.Matches, ".*exports.go")
+
+	// package which can not be type checked is skipped alone
+	diagnostics := gofile.TakeDiagnostics()
+	c.Assert(diagnostics, C.HasLen, 1)
+	c.Check(diagnostics[0].Code, C.Equals, gofile.CodeNoCallGraph)
+	c.Check(diagnostics[0].Message, C.Matches, "example.com/exports/broken .*")
+}
+
+// implementers resolved by call graph analysis are not reported as unresolved
+func (*analysisSuite) TestGetInterfacesResolved(c *C.C) {
+	gofile.TakeDiagnostics()
+	if _implementerIfc == nil {
+		parseTmpCode()
+	}
+	pkgs, err := loadPackages(filepath.Join("testdata", "exports"), []string{"./..."})
+	c.Assert(err, C.IsNil)
+	objects := GetInterfaces(pkgs, AnalyzeExports(pkgs))
+	c.Check(objects, C.HasLen, 6)
+	for _, d := range gofile.TakeDiagnostics() {
+		c.Check(d.Code, C.Not(C.Equals), gofile.CodeUnresolvedExport, C.Commentf("%s", d))
+	}
+
+	// without call graph analysis they are
+	GetInterfaces(pkgs, nil)
+	var unresolved []string
+	for _, d := range gofile.TakeDiagnostics() {
+		if d.Code == gofile.CodeUnresolvedExport {
+			unresolved = append(unresolved, filepath.Base(d.Position.File))
+		}
+	}
+	c.Check(unresolved, C.DeepEquals, []string{"exports.go", "exports.go", "helper.go"})
+}
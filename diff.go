@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	gofile "./writeGoFile"
+)
+
+// run diff command, api found by the same flags as other commands is compared with baseline file
+// or with the same input at git revision, exit code is exitFindings if any change is breaking
+func runDiff(args []string) (code int) {
+	fs := newFlagSet("diff", "compare api with baseline, every change is printed and exit code is 1 "+
+		"if any change is breaking.")
+	in := addInputFlags(fs)
+	baseline := fs.String("baseline", "", "model file, introspection xml file or dir of xml files of baseline api")
+	baseRev := fs.String("baseRev", "", "git revision of baseline api, api is found by the same flags "+
+		"and packages in tree at revision")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	if (*baseline == "") == (*baseRev == "") {
+		fmt.Fprintln(fs.Output(), "one of -baseline and -baseRev should be set")
+		fs.Usage()
+		return exitError
+	}
+
+	current, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("load current api failed, err: ", err)
+		return exitError
+	}
+	var base []*gofile.DBusObject
+	// problems of baseline are not problems of current api, they are only logged
+	// and do not fail command
+	diagnostics := gofile.TakeDiagnostics()
+	if *baseline != "" {
+		base, err = loadBaseline(*baseline, in)
+	} else {
+		err = atRevision(*baseRev, func(tree *revisionTree) error {
+			revIn, patterns, err := tree.inputs(in, fs.Args())
+			if err != nil {
+				return err
+			}
+			base, err = revIn.load(patterns)
+			return err
+		})
+	}
+	for _, d := range gofile.TakeDiagnostics() {
+		log.Println("baseline:", d)
+	}
+	for _, d := range diagnostics {
+		gofile.Report(d)
+	}
+	if err != nil {
+		log.Println("load baseline api failed, err: ", err)
+		return exitError
+	}
+
+	changes := gofile.DiffModels(gofile.NewModel(base), gofile.NewModel(current))
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if gofile.HasBreakingChange(changes) {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// load baseline file, it is model file or introspect xml
+func loadBaseline(filename string, in *inputFlags) ([]*gofile.DBusObject, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() || strings.EqualFold(filepath.Ext(filename), ".xml") {
+		return LoadXmlObjects(filename, in.serviceName, in.busType, in.busPath)
+	}
+	model, err := gofile.LoadModel(filename)
+	if err != nil {
+		return nil, err
+	}
+	return model.DBusObjects(), nil
+}
+
+// tree checked out at git revision
+type revisionTree struct {
+	// top dir of current tree, and current dir relative to it
+	top    string
+	prefix string
+	// top dir of tree at revision
+	dir string
+}
+
+// map path in current tree to the same file in tree at revision, relative path is kept as
+// current dir is the same dir at revision, path out of tree is rejected, as it would be
+// the same file at every revision
+func (t *revisionTree) mapPath(path string) (string, error) {
+	if path == "" {
+		return path, nil
+	}
+	abs := path
+	if !filepath.IsAbs(path) {
+		abs = filepath.Join(t.top, t.prefix, path)
+	}
+	rel, err := filepath.Rel(t.top, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is out of git tree %s, it can not be loaded at revision", path, t.top)
+	}
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(t.dir, rel), nil
+}
+
+// get input flags and package patterns which point to files at revision
+func (t *revisionTree) inputs(in *inputFlags, patterns []string) (*inputFlags, []string, error) {
+	revIn := *in
+	var err error
+	for _, path := range []*string{&revIn.model, &revIn.fromXml, &revIn.config} {
+		*path, err = t.mapPath(*path)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var revPatterns []string
+	for _, pattern := range patterns {
+		pattern, err = t.mapPath(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		revPatterns = append(revPatterns, pattern)
+	}
+	return &revIn, revPatterns, nil
+}
+
+// call fn in the same dir of tree checked out at git revision, so relative paths
+// point to files at revision, absolute paths are mapped by revisionTree
+func atRevision(rev string, fn func(tree *revisionTree) error) error {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	prefix, err := gitOutput("rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	tmpDir, err := ioutil.TempDir("", "deepinAutoWrite")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	worktree := filepath.Join(tmpDir, "tree")
+	_, err = gitOutput("worktree", "add", "--detach", worktree, rev)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, err := gitOutput("worktree", "remove", "--force", worktree)
+		if err != nil {
+			log.Println("remove worktree failed, err: ", err)
+		}
+	}()
+
+	current, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	err = os.Chdir(filepath.Join(worktree, prefix))
+	if err != nil {
+		return err
+	}
+	defer os.Chdir(current)
+	return fn(&revisionTree{top: filepath.FromSlash(top), prefix: filepath.FromSlash(prefix), dir: worktree})
+}
+
+// run git command in current dir, trimmed output is returned
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed, err: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	C "gopkg.in/check.v1"
+
+	gofile "./writeGoFile"
+)
+
+type diffSuite struct{}
+
+var _ = C.Suite(&diffSuite{})
+
+func (*diffSuite) TestMapPath(c *C.C) {
+	tree := &revisionTree{top: "/src/daemon", prefix: "accounts", dir: "/tmp/tree"}
+	for path, expected := range map[string]string{
+		"":                          "",
+		"./...":                     "./...",
+		"../audio":                  "../audio",
+		"/src/daemon/api.json":      "/tmp/tree/api.json",
+		"/src/daemon/accounts/...":  "/tmp/tree/accounts/...",
+		"/src/daemon/accounts/user": "/tmp/tree/accounts/user",
+	} {
+		mapped, err := tree.mapPath(path)
+		c.Check(err, C.IsNil)
+		c.Check(mapped, C.Equals, expected, C.Commentf("path %q", path))
+	}
+	for _, path := range []string{"../../lib", "/src/lib/dbusutil", "/src"} {
+		_, err := tree.mapPath(path)
+		c.Check(err, C.ErrorMatches, ".* is out of git tree /src/daemon, .*", C.Commentf("path %q", path))
+	}
+}
+
+// save model of one interface to file
+func saveDiffModel(c *C.C, filename string, itfName string) {
+	object := gofile.NewDBusObject()
+	object.SetServiceName("com.deepin.Test")
+	object.SetBusType("session")
+	object.SetDBusPath("/com/deepin/Test")
+	object.SetInterfaceName(itfName)
+	c.Assert(gofile.SaveModel(gofile.DiskOutput{}, filename, gofile.NewModel([]*gofile.DBusObject{object})), C.IsNil)
+}
+
+// run git command in dir
+func runGit(c *C.C, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"},
+		args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	c.Assert(err, C.IsNil, C.Commentf("git %v: %s", args, out))
+}
+
+// absolute model path is loaded from tree at revision, not from current tree
+func (*diffSuite) TestDiffAtRevision(c *C.C) {
+	if _, err := exec.LookPath("git"); err != nil {
+		c.Skip("git is not found")
+	}
+	dir, err := filepath.EvalSymlinks(c.MkDir())
+	c.Assert(err, C.IsNil)
+	filename := filepath.Join(dir, "api.json")
+	runGit(c, dir, "init", "-q")
+	saveDiffModel(c, filename, "com.deepin.Test")
+	runGit(c, dir, "add", "api.json")
+	runGit(c, dir, "commit", "-q", "-m", "api")
+	saveDiffModel(c, filename, "com.deepin.Test2")
+
+	current, err := os.Getwd()
+	c.Assert(err, C.IsNil)
+	c.Assert(os.Chdir(dir), C.IsNil)
+	defer os.Chdir(current)
+	c.Check(runDiff([]string{"-baseRev", "HEAD", "-model", filename}), C.Equals, exitFindings)
+	c.Check(runDiff([]string{"-baseRev", "HEAD", "-model", "api.json"}), C.Equals, exitFindings)
+	c.Check(runDiff([]string{"-baseline", filename, "-model", "api.json"}), C.Equals, exitOK)
+	c.Check(runDiff([]string{"-baseRev", "HEAD", "-model", "/dev/null"}), C.Equals, exitError)
+}
+
+// diagnostics of baseline are not written as diagnostics of current api
+func (*diffSuite) TestDiffBaselineDiagnostics(c *C.C) {
+	if _, err := exec.LookPath("git"); err != nil {
+		c.Skip("git is not found")
+	}
+	dir, err := filepath.EvalSymlinks(c.MkDir())
+	c.Assert(err, C.IsNil)
+	runGit(c, dir, "init", "-q")
+	object := gofile.NewDBusObject()
+	object.SetServiceName("com.deepin.Test")
+	object.SetBusType("session")
+	object.SetDBusPath("/com/deepin/Test")
+	object.SetInterfaceName("com.deepin.Test")
+	object.AddMethod(&gofile.DBusMethod{Name: "Old"})
+	filename := filepath.Join(dir, "api.json")
+	c.Assert(gofile.SaveModel(gofile.DiskOutput{}, filename, gofile.NewModel([]*gofile.DBusObject{object})), C.IsNil)
+	config := "interfaces:\n  - match: com.deepin.Test\n    skipMethods: [Old]\n"
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644), C.IsNil)
+	runGit(c, dir, "add", "api.json", "config.yaml")
+	runGit(c, dir, "commit", "-q", "-m", "api")
+	saveDiffModel(c, filename, "com.deepin.Test")
+
+	current, err := os.Getwd()
+	c.Assert(err, C.IsNil)
+	c.Assert(os.Chdir(dir), C.IsNil)
+	defer os.Chdir(current)
+	diagnosticsFile := filepath.Join(c.MkDir(), "diagnostics.txt")
+	c.Check(runDiff([]string{"-baseRev", "HEAD", "-model", "api.json", "-config", "config.yaml",
+		"-diagnosticsFile", diagnosticsFile}), C.Equals, exitOK)
+	data, err := ioutil.ReadFile(diagnosticsFile)
+	c.Assert(err, C.IsNil)
+	c.Check(string(data), C.Equals, "")
+}
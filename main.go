@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"unicode"
 
 	"go/ast"
@@ -29,207 +33,616 @@ type Implementer interface {
 }
 `
 
-// mark need replaced interfaces in module file
-var replaceInterface = "replaceInterfaceMark"
-
-// mark need replaced package in module file
-var replacePackage = "replacePackageMark"
+// exit codes of commands
+const (
+	exitOK = 0
+	// command works but finds problems, e.g. breaking changes or stale files
+	exitFindings = 1
+	// command can not work, e.g. bad flags or source can not be loaded
+	exitError = 2
+)
 
-// unit test name
-var writeTestFileName = "Test_%s_DBusWriteXml_test.go"
+// command of tool, all commands find bus objects by the same engine, see inputFlags
+type command struct {
+	name  string
+	short string
+	run   func(args []string) int
+}
 
-var writeXml = false
-var writeGo = false
+var commands = []*command{
+	{name: "scan", short: "find bus objects and save them as model", run: runScan},
+	{name: "xml", short: "generate introspection xml", run: runXml},
+	{name: "go", short: "generate go proxy code", run: runGo},
+	{name: "diff", short: "compare api with baseline, exit with 1 if any change is breaking", run: runDiff},
+	{name: "check", short: "check if generated files are up to date, exit with 1 if not", run: runCheck},
+	{name: "lint", short: "report problems of bus objects, exit with 1 if any", run: runLint},
+	{name: "docs", short: "generate markdown docs of api", run: runDocs},
+}
 
 // func main
 func main() {
-	// read param
-	var file string
-	flag.StringVar(&file, "filePath", "", "")
-	flag.BoolVar(&writeXml, "writeXml", false, "")
-	flag.BoolVar(&writeGo, "writeGo", false, "")
-	flag.Parse()
 	// set log flags
 	log.SetFlags(log.Lshortfile)
-	// parse code
-	parseTmpCode()
+	os.Exit(runCommand(os.Args[1:]))
+}
+
+// run command named by first arg
+func runCommand(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitError
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		// help of command is usage of its flags
+		if len(args) > 1 {
+			return runCommand([]string{args[1], "-h"})
+		}
+		usage()
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage()
+	return exitError
+}
+
+func usage() {
+	out := os.Stderr
+	fmt.Fprintln(out, "usage: deepinAutoWrite <command> [flags] [packages]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-6s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "packages are dirs or patterns like ./..., current dir is used if none is given.")
+	fmt.Fprintln(out, "run 'deepinAutoWrite help <command>' for flags of command.")
+}
+
+// create flag set of command, usage lists its flags
+func newFlagSet(name string, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "usage: deepinAutoWrite %s [flags] [packages]\n\n", name)
+		fmt.Fprintf(out, "%s\n\nflags:\n", description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse flags of command, exit code is returned if command should not go on
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return exitOK, false
+	}
+	if err != nil {
+		return exitError, false
+	}
+	return exitOK, true
+}
+
+// flags to find bus objects, they are shared by all commands
+type inputFlags struct {
+	model       string
+	fromXml     string
+	serviceName string
+	busType     string
+	busPath     string
+	order       string
+	config      string
 
-	// convert absolute path
-	if filepath.IsAbs(file) {
-		// get current path
-		current, err := os.Getwd()
+	// format and file of diagnostics, see writeDiagnostics
+	diagnostics     string
+	diagnosticsFile string
+	strict          bool
+	// diagnostics are written to it if file is not set
+	diagnosticsOut io.Writer
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	in := &inputFlags{}
+	fs.StringVar(&in.model, "model", "", "load bus objects from model file saved by scan instead of packages")
+	fs.StringVar(&in.fromXml, "fromXml", "",
+		"load bus objects from introspection xml file, or xml files in dir, instead of packages")
+	fs.StringVar(&in.serviceName, "serviceName", "", "service name of objects loaded by -fromXml")
+	fs.StringVar(&in.busType, "busType", "", "bus type of objects loaded by -fromXml, system or session")
+	fs.StringVar(&in.busPath, "busPath", "", "path of root node which has no name in xml loaded by -fromXml")
+	fs.StringVar(&in.order, "order", gofile.OrderName,
+		"order of objects and members in output, name or source (order of declarations)")
+	fs.StringVar(&in.config, "config", "", "config file to filter and fix bus objects, see docs/config.md")
+	fs.StringVar(&in.diagnostics, "diagnostics", gofile.DiagnosticsText,
+		"format of diagnostics which explain what is skipped and why, text, json, sarif (SARIF 2.1.0) "+
+			"or checkstyle (checkstyle xml)")
+	fs.StringVar(&in.diagnosticsFile, "diagnosticsFile", "", "file to write diagnostics, stderr if empty")
+	fs.BoolVar(&in.strict, "strict", false, "fail with exit code 1 if any warning is reported")
+	in.diagnosticsOut = os.Stderr
+	return in
+}
+
+// load bus objects from model file, introspection xml or packages matched by patterns,
+// config is applied and objects are sorted, so output is the same in every run for the same input
+func (in *inputFlags) load(patterns []string) ([]*gofile.DBusObject, error) {
+	var objects []*gofile.DBusObject
+	var config *gofile.Config
+	var err error
+	err = gofile.CheckDiagnosticsFormat(in.diagnostics)
+	if err != nil {
+		return nil, err
+	}
+	// check config first, so misspelled config does not wait for scanning
+	if in.config != "" {
+		config, err = gofile.LoadConfig(in.config)
 		if err != nil {
-			log.Fatal(err)
-			return
+			return nil, err
+		}
+	}
+	switch {
+	case in.model != "" && in.fromXml != "":
+		return nil, errors.New("-model and -fromXml can not be used together")
+	case in.model != "":
+		var model *gofile.Model
+		model, err = gofile.LoadModel(in.model)
+		if err == nil {
+			objects = model.DBusObjects()
 		}
-		relFile, err := filepath.Rel(current, file)
+	case in.fromXml != "":
+		// for services not written in go
+		objects, err = LoadXmlObjects(in.fromXml, in.serviceName, in.busType, in.busPath)
+	default:
+		objects, err = ScanObjects(patterns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		objects = config.Apply(objects)
+	}
+	err = gofile.SortDBusObjects(objects, in.order)
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// write diagnostics reported while command runs, exit code is raised to exitFindings if any error
+// is reported, or any warning in strict mode
+func (in *inputFlags) finish(code int) int {
+	diagnostics := gofile.TakeDiagnostics()
+	gofile.SortDiagnostics(diagnostics)
+	// unknown format is reported when loading
+	if gofile.CheckDiagnosticsFormat(in.diagnostics) != nil {
+		return code
+	}
+	out := in.diagnosticsOut
+	if in.diagnosticsFile != "" {
+		file, err := os.Create(in.diagnosticsFile)
 		if err != nil {
-			log.Printf("convert absolute path to rel path failed, err: %v \n", err)
-			return
+			log.Println("write diagnostics failed, err: ", err)
+			return exitError
+		}
+		defer file.Close()
+		out = file
+	}
+	err := gofile.WriteDiagnostics(out, diagnostics, in.diagnostics)
+	if err != nil {
+		log.Println("write diagnostics failed, err: ", err)
+		return exitError
+	}
+	if code == exitOK && gofile.HasFailure(diagnostics, in.strict) {
+		return exitFindings
+	}
+	return code
+}
+
+// find bus objects and save them as model
+func runScan(args []string) (code int) {
+	fs := newFlagSet("scan", "find bus objects and save them as model, see docs/model.md.")
+	in := addInputFlags(fs)
+	modelFile := fs.String("o", "-", "file to save model, yaml if it ends with .yaml or .yml, "+
+		"json if not, - prints json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	objects, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
+	}
+	err = writeModelFile(gofile.DiskOutput{}, *modelFile, objects)
+	if err != nil {
+		log.Println("write model failed, err: ", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// generate introspection xml
+func runXml(args []string) (code int) {
+	fs := newFlagSet("xml", "generate introspection xml of bus objects.")
+	in := addInputFlags(fs)
+	outDir := fs.String("outDir", "", "save xml and config.json of go-dbus-factory to dir of each service "+
+		"in dir, xml of each interface is saved beside its package if empty")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	objects, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
+	}
+	err = writeXmlFiles(gofile.DiskOutput{}, *outDir, objects)
+	if err != nil {
+		log.Println("write xml failed, err: ", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// generate go proxy code
+func runGo(args []string) (code int) {
+	fs := newFlagSet("go", "generate go proxy code of bus objects, in layout of go-dbus-factory.")
+	in := addInputFlags(fs)
+	outDir := fs.String("outDir", "", "save proxy code to dir of each service in dir, code is printed if empty")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	objects, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
+	}
+	if *outDir == "" {
+		err = printGoFiles(objects)
+	} else {
+		err = writeGoFiles(gofile.DiskOutput{}, *outDir, objects)
+	}
+	if err != nil {
+		log.Println("write go failed, err: ", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// regenerate files in memory and compare them with files on disk, nothing is written
+func runCheck(args []string) (code int) {
+	fs := newFlagSet("check", "generate files in memory and compare them with files on disk, "+
+		"nothing is written.\nstale files are printed as unified diff and exit code is 1.")
+	in := addInputFlags(fs)
+	outDir := fs.String("outDir", "", "check proxy code saved by 'go -outDir', and files saved by "+
+		"'xml -outDir' if -xml is set")
+	checkXml := fs.Bool("xml", false, "check xml, it is beside package if -outDir is empty")
+	modelFile := fs.String("modelFile", "", "check model file saved by 'scan -o'")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	if *outDir == "" && !*checkXml && *modelFile == "" {
+		fmt.Fprintln(fs.Output(), "nothing to check, one of -outDir, -xml and -modelFile should be set")
+		fs.Usage()
+		return exitError
+	}
+	objects, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
+	}
+
+	out := gofile.NewCheckOutput(os.Stdout)
+	if *modelFile != "" {
+		err = writeModelFile(out, *modelFile, objects)
+	}
+	if err == nil && *outDir != "" {
+		err = writeGoFiles(out, *outDir, objects)
+	}
+	if err == nil && *checkXml {
+		err = writeXmlFiles(out, *outDir, objects)
+	}
+	if err != nil {
+		log.Println("generate failed, err: ", err)
+		return exitError
+	}
+	if stale := out.GetStaleFiles(); len(stale) > 0 {
+		log.Printf("%d generated files are stale, regenerate them \n", len(stale))
+		return exitFindings
+	}
+	return exitOK
+}
+
+// report problems of bus objects
+func runLint(args []string) (code int) {
+	fs := newFlagSet("lint", "report problems of bus objects to stdout, exit code is 1 if any error is found, "+
+		"or any warning with -strict.")
+	in := addInputFlags(fs)
+	in.diagnosticsOut = os.Stdout
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	objects, err := in.load(fs.Args())
+	if err != nil {
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
+	}
+	// problems are diagnostics, they are written with ones found when loading,
+	// problems reported when loading are not reported again
+	var problems []*gofile.Diagnostic
+	for _, d := range gofile.LintDBusObjects(objects) {
+		if !gofile.IsReported(d) {
+			problems = append(problems, d)
 		}
-		file = relFile
 	}
+	for _, d := range problems {
+		gofile.Report(d)
+	}
+	return exitOK
+}
 
-	// write and execute unittest
-	err := WriteAndExecuteUnitTest(file)
+// generate markdown docs of api
+func runDocs(args []string) (code int) {
+	fs := newFlagSet("docs", "generate markdown docs of api of bus objects.")
+	in := addInputFlags(fs)
+	docsFile := fs.String("o", "-", "file to save docs, - prints docs")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	defer func() {
+		code = in.finish(code)
+	}()
+	objects, err := in.load(fs.Args())
 	if err != nil {
-		log.Println("write and execute file failed, err: ", err)
+		log.Println("find bus objects failed, err: ", err)
+		return exitError
 	}
+	if *docsFile == "-" {
+		err = gofile.WriteDocs(os.Stdout, objects)
+	} else {
+		err = gofile.SaveDocs(gofile.DiskOutput{}, *docsFile, objects)
+	}
+	if err != nil {
+		log.Println("write docs failed, err: ", err)
+		return exitError
+	}
+	return exitOK
 }
 
-// write and execute unit test
-func WriteAndExecuteUnitTest(file string) error {
-	// walk filepath
-	err := filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
-		// check if open file failed
+// load objects of introspect xml file, or all xml files in dir
+func LoadXmlObjects(file string, serviceName string, busType string, busPath string) ([]*gofile.DBusObject, error) {
+	filenames := []string{file}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		filenames, err = filepath.Glob(filepath.Join(file, "*.xml"))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		// check if path is empty
-		if path == "" {
-			return nil
+	}
+	var objects []*gofile.DBusObject
+	for _, filename := range filenames {
+		xmlObjects, err := gofile.LoadXml(filename, serviceName, busType, busPath)
+		if err != nil {
+			return nil, err
 		}
-		// check if is dir
-		if !info.IsDir() {
-			return nil
+		objects = append(objects, xmlObjects...)
+	}
+	return objects, nil
+}
+
+// find bus objects of packages matched by patterns, current dir is used if no pattern
+func ScanObjects(patterns []string) ([]*gofile.DBusObject, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	if _implementerIfc == nil {
+		parseTmpCode()
+	}
+	var objects []*gofile.DBusObject
+	for _, query := range groupPatterns(patterns) {
+		pkgs, err := loadPackages(query.dir, query.patterns)
+		if err != nil {
+			return nil, err
 		}
-		// get interface map
-		interfacesMap, busObjects, err := GetInterfaces(path)
+		// find exports of all packages first, so exports in other packages are known
+		exportSites := AnalyzeExports(pkgs)
+		objects = append(objects, GetInterfaces(pkgs, exportSites)...)
+	}
+	if len(objects) == 0 {
+		gofile.Reportf(nil, gofile.SeverityWarning, gofile.CodeNoObjects,
+			"no bus object is found in %s", strings.Join(patterns, " "))
+	}
+	return objects, nil
+}
+
+// save model of objects
+func writeModelFile(out gofile.Output, filename string, objects []*gofile.DBusObject) error {
+	err := gofile.SaveModel(out, filename, gofile.NewModel(objects))
+	if err != nil {
+		return err
+	}
+	if filename != "-" {
+		logWritten(out, "model", filename)
+	}
+	return nil
+}
+
+// print proxy code of each go package
+func printGoFiles(objects []*gofile.DBusObject) error {
+	pkgs, pkgObjects := groupByPackage(objects)
+	for _, pkg := range pkgs {
+		sf := gofile.NewProxySourceFile(pkg)
+		sf.WriteDBusObjects(pkgObjects[pkg])
+		err := sf.Print()
 		if err != nil {
-			log.Println("get interface map failed, err: ", err)
 			return err
 		}
+	}
+	return nil
+}
 
-		// check if interface map dont has instance implement interface method
-		if len(interfacesMap) == 0 {
-			log.Println(path, " interface is empty")
-			return nil
-		}
+// write proxy code of each service to its own package in outDir
+func writeGoFiles(out gofile.Output, outDir string, objects []*gofile.DBusObject) error {
+	filenames, err := gofile.SaveProxyFiles(out, outDir, objects)
+	logWritten(out, "go", filenames...)
+	return err
+}
 
-		// unique slice
-		for key, value := range interfacesMap {
-			interfacesMap[key] = UniqueSlice(value)
+// write introspect xml and config.json of go-dbus-factory to service dir in outDir,
+// or xml of each object beside its package if outDir is empty
+func writeXmlFiles(out gofile.Output, outDir string, objects []*gofile.DBusObject) error {
+	if outDir != "" {
+		filenames, err := gofile.SaveFactoryFiles(out, outDir, objects)
+		logWritten(out, "factory", filenames...)
+		return err
+	}
+	for _, busObject := range objects {
+		dir := "."
+		if source := busObject.GetSource(); source != nil && source.Dir != "" {
+			dir = source.Dir
 		}
-
-		if writeGo {
-			for pkg, busObject := range busObjects {
-				sf := gofile.NewSourceFile(pkg)
-
-				sf.AddGoImport("errors")
-				sf.AddGoImport("fmt")
-				sf.AddGoImport("unsafe")
-				sf.AddGoImport("github.com/godbus/dbus")
-				sf.AddGoImport("pkg.deepin.io/lib/dbusutil")
-				sf.AddGoImport("pkg.deepin.io/lib/dbusutil/proxy")
-
-				sf.GoBody.Pn("/* prevent compile error */")
-				sf.GoBody.Pn("var _ = errors.New")
-				sf.GoBody.Pn("var _ dbusutil.SignalHandlerId")
-				sf.GoBody.Pn("var _ = fmt.Sprintf")
-				sf.GoBody.Pn("var _ unsafe.Pointer")
-				sf.GoBody.Pn("")
-
-				sf.GoBody.WriteDBusObjects(busObject)
-				_ = sf.Print()
-			}
+		filename, err := gofile.SaveXml(out, dir, busObject)
+		if err != nil {
+			log.Println("write xml file failed, err: ", err)
+			continue
 		}
+		logWritten(out, "xml", filename)
+	}
+	return nil
+}
 
-		if writeXml {
-			// create xml file
-			for pkg, interfaces := range interfacesMap {
-				// check if package is empty or instances is nil
-				if pkg == "" || len(interfaces) == 0 {
-					continue
-				}
-				// get replace text
-				replaceText := FormatImplementers(interfaces)
-				// target file path
-				// replace instance in module
-				resultText := ReplaceText(ModuleStr, replaceInterface, replaceText)
-				targetPath := fmt.Sprintf(path+"/"+writeTestFileName, pkg)
-				// replace package in target path
-				resultText = ReplaceText(resultText, replacePackage, "package "+pkg)
-				fObj, err := os.Create(targetPath)
-				if err != nil {
-					log.Println("open or create target file failed, err: ", err)
-					continue
-				}
-				// write string to file
-				_, err = fObj.WriteString(resultText)
-				if err != nil {
-					log.Println("write string to file failed, err: ", err)
-					continue
-				}
-				// check if need update file, if not, dont need to create unit test file
-				err = ExecuteUnitTest(path)
-				if err != nil {
-					log.Println("execute unit test failed, err: ", err)
-					continue
-				}
-				if err := os.Remove(targetPath); err != nil {
-					log.Println("remove file failed, err: ", err)
-					return err
-				}
-			}
+// log files written, nothing is written when checking
+func logWritten(out gofile.Output, kind string, filenames ...string) {
+	if _, ok := out.(*gofile.CheckOutput); ok {
+		return
+	}
+	for _, filename := range filenames {
+		log.Printf("write %s file success, %s \n", kind, filename)
+	}
+}
+
+// group objects by name of go package which declares them, objects without source
+// use package of their service, packages are kept in order they appear
+func groupByPackage(objects []*gofile.DBusObject) ([]string, map[string][]*gofile.DBusObject) {
+	var pkgs []string
+	pkgObjects := make(map[string][]*gofile.DBusObject)
+	for _, busObject := range objects {
+		var pkg string
+		if source := busObject.GetSource(); source != nil {
+			pkg = source.Package
+		} else {
+			_, pkg = gofile.GetServicePackage(busObject.GetServiceName())
 		}
-		return nil
-	})
-	return err
+		if _, ok := pkgObjects[pkg]; !ok {
+			pkgs = append(pkgs, pkg)
+		}
+		pkgObjects[pkg] = append(pkgObjects[pkg], busObject)
+	}
+	return pkgs, pkgObjects
 }
 
-func GetInterfaces(filepath string) (map[string][]string, map[string][]*gofile.DBusObject, error) {
-	interfacesMap := make(map[string][]string)
-	busObjects := make(map[string][]*gofile.DBusObject)
-	var busContainer = gofile.NewDBusContainer()
+// load packages matched by patterns in dir with full type info, so types from dependencies
+// keep real type, packages are sorted, so order of objects is the same in every run
+func loadPackages(dir string, patterns []string) ([]*packages.Package, error) {
 	cfg := &packages.Config{
-		Mode: packages.NeedFiles,
+		Dir: dir,
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
 	}
-	pkgs, err := packages.Load(cfg, filepath)
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		log.Println("load package error, ", err)
-		return nil, nil, err
+		return nil, fmt.Errorf("load packages failed, err: %v", err)
 	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].PkgPath < pkgs[j].PkgPath
+	})
+	return pkgs, nil
+}
+
+// find bus objects of packages loaded by loadPackages, objects are in order of packages
+// and declarations
+func GetInterfaces(pkgs []*packages.Package, exportSites map[string][]*exportSite) []*gofile.DBusObject {
+	var busObjects []*gofile.DBusObject
+	resolvedCalls := resolvedExportCalls(exportSites)
 	for _, pkg := range pkgs {
-		var files []*ast.File
-		fSet := token.NewFileSet()
 		if len(pkg.GoFiles) == 0 {
 			continue
 		}
-		for _, goFile := range pkg.GoFiles {
-			f, err := parser.ParseFile(fSet, goFile, nil, 0)
-			if err != nil {
-				log.Println(err)
-				continue
-			}
-			files = append(files, f)
-		}
-
-		info := types.Info{
-			Defs:   make(map[*ast.Ident]types.Object),
-			Scopes: make(map[ast.Node]*types.Scope),
+		// exports are found by object name, container can not be shared by packages
+		var busContainer = gofile.NewDBusContainer()
+		files := pkg.Syntax
+		info := pkg.TypesInfo
+		fSet := pkg.Fset
+		// check if dependencies can be resolved, if not, use stub importer instead
+		if hasUnresolvedDeps(pkg) {
+			reportStubImporter(pkg)
+			fSet, files, info = checkWithStubImporter(pkg)
+		} else {
+			reportPackageParseErrors(pkg)
 		}
-		var conf types.Config
-		conf.Error = func(err error) {
+		if info == nil {
+			continue
 		}
-		conf.Importer = &importer{}
-		_, _ = conf.Check(pkg.PkgPath, fSet, files, &info)
-
-		for iNode, _ := range info.Scopes {
-			if iNode, ok := iNode.(*ast.File); ok {
-				busEls := gofile.GetDBusPathName(iNode)
-				if busEls == nil {
-					continue
+
+		// collect export calls of all files
+		for _, file := range files {
+			busEls, unresolved := gofile.GetDBusPathName(fSet, file, info)
+			busContainer.AddDBusElem(busEls...)
+			// implementers may be resolved by call graph analysis, even in other packages
+			for _, u := range unresolved {
+				if !resolvedCalls[u.Call] {
+					u.Report()
 				}
-				busContainer.AddDBusElem(busEls...)
+			}
+		}
+		// add exports which can only be found by call graph analysis
+		for _, ident := range sortedDefs(info) {
+			typeName, ok := info.Defs[ident].(*types.TypeName)
+			if !ok || busContainer.GetDBusElemByObj(typeName.Name()) != nil {
+				continue
+			}
+			for _, site := range exportSites[exportTypeKey(typeName)] {
+				busContainer.AddDBusElem(&gofile.DBusElem{
+					DBusPath:    site.path,
+					DBusObjName: typeName.Name(),
+					ServiceName: site.serviceName,
+					BusType:     site.busType,
+					Position:    site.position,
+					End:         site.end,
+				})
 			}
 		}
 
 		// refresh
 		busContainer.RefreshDBusObj(files)
-		busContainer.RefreshDBusPath(files)
-		busContainer.RefreshDBusInterface(files)
+		busContainer.RefreshDBusPath(files, info)
+		busContainer.RefreshDBusInterface(files, info)
+		// trace service of each export
+		tracer := gofile.NewServiceTracer(info)
+		tracer.Trace(files)
+		busContainer.RefreshDBusService(tracer)
 
-		for ident, obj := range info.Defs {
+		for _, ident := range sortedDefs(info) {
+			obj := info.Defs[ident]
 			if obj == nil {
 				continue
 			}
@@ -252,17 +665,62 @@ func GetInterfaces(filepath string) (map[string][]string, map[string][]*gofile.D
 			}
 
 			busElem := busContainer.GetDBusElemByObj(ident.Name)
-			busObject := gofile.NewDBusObject()
-			busObject.SetDBusPath(busElem.DBusPath)
-			busObject.SetTypesNamed(named)
-			busObjects[obj.Pkg().Name()] = append(busObjects[obj.Pkg().Name()], busObject)
+			if busElem == nil {
+				gofile.Reportf(gofile.NewNameRange(fSet, obj), gofile.SeverityWarning, gofile.CodeNoExport,
+					"skip type %s.%s, reason: it implements interface but export of it is not found",
+					obj.Pkg().Path(), ident.Name)
+				continue
+			}
+			// export call is where service, path and interface come from
+			exportPosition := gofile.RangeOf(busElem.Position, busElem.End)
+			if exportPosition == nil {
+				exportPosition = gofile.NewNameRange(fSet, obj)
+			}
+			if busElem.ServiceName == "" || busElem.BusType == "" {
+				gofile.Reportf(exportPosition, gofile.SeverityWarning, gofile.CodeUnknownService,
+					"can not determine service name or bus type of %s.%s", obj.Pkg().Path(), ident.Name)
+			}
+			if busElem.DBusPath == "" {
+				gofile.Reportf(exportPosition, gofile.SeverityWarning, gofile.CodeUnresolvedPath,
+					"can not determine path of %s.%s", obj.Pkg().Path(), ident.Name)
+			}
+			if busElem.DBusInterface == "" {
+				gofile.Reportf(exportPosition, gofile.SeverityWarning, gofile.CodeUnresolvedInterface,
+					"can not determine interface name of %s.%s", obj.Pkg().Path(), ident.Name)
+			}
+			busObject := gofile.NewDBusObjectFromElem(fSet, busElem, named)
+			busObject.SetSourceDir(getPackageDir(pkg))
+			busObjects = append(busObjects, busObject)
+		}
+	}
+	return busObjects
+}
 
-			element := fmt.Sprintf("&%v{}", ident)
-			interfacesMap[obj.Pkg().Name()] = append(interfacesMap[obj.Pkg().Name()], element)
+// report package is checked with stub importer, position of first error explains which
+// dependency can not be resolved
+func reportStubImporter(pkg *packages.Package) {
+	var position *gofile.Position
+	reason := "dependencies can not be resolved"
+	if pkgErr := getUnresolvedError(pkg); pkgErr != nil {
+		position = parseErrorPosition(pkgErr.Pos)
+		reason = pkgErr.Msg
+	}
+	gofile.Reportf(position, gofile.SeverityWarning, gofile.CodeStubImporter,
+		"check %s with stub importer, types from dependencies are unknown, reason: %s", pkg.PkgPath, reason)
+}
 
-		}
+// get dir of package, it is relative to current dir if package is in it
+func getPackageDir(pkg *packages.Package) string {
+	dir := filepath.Dir(pkg.GoFiles[0])
+	current, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(current, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
 	}
-	return interfacesMap, busObjects, nil
+	return rel
 }
 
 // record _implementerIfc message
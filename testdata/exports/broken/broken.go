@@ -0,0 +1,16 @@
+package broken
+
+import (
+	"example.com/missing"
+
+	"pkg.deepin.io/lib/dbusutil"
+)
+
+type Broken struct{}
+
+func (*Broken) GetInterfaceName() string { return "com.deepin.Test.Broken" }
+
+func Export(service *dbusutil.Service) error {
+	missing.Init()
+	return service.Export("/com/deepin/Test/Broken", &Broken{})
+}
@@ -0,0 +1,63 @@
+package exports
+
+import (
+	"example.com/exports/helper"
+
+	"pkg.deepin.io/lib/dbusutil"
+)
+
+type Manager struct{}
+
+func (*Manager) GetInterfaceName() string { return "com.deepin.Test.Manager" }
+
+type User struct{}
+
+func (*User) GetInterfaceName() string { return "com.deepin.Test.User" }
+
+type Group struct{}
+
+func (*Group) GetInterfaceName() string { return "com.deepin.Test.Group" }
+
+type Session struct{}
+
+func (*Session) GetInterfaceName() string { return "com.deepin.Test.Session" }
+
+type Device struct{}
+
+func (*Device) GetInterfaceName() string { return "com.deepin.Test.Device" }
+
+// exporter which is not dbusutil.Service
+type fakeService struct{}
+
+func (fakeService) Export(path string, v interface{}) {}
+
+func Start() error {
+	service, err := dbusutil.NewSessionService()
+	if err != nil {
+		return err
+	}
+	// through helper func
+	err = helper.Export(service, "/com/deepin/Test/Manager", &Manager{})
+	if err != nil {
+		return err
+	}
+	// the same helper func with another path
+	err = helper.Export(service, "/com/deepin/Test/Device", &Device{})
+	if err != nil {
+		return err
+	}
+	// through interface value
+	var implementer dbusutil.Implementer = &User{}
+	err = service.Export("/com/deepin/Test/User", implementer)
+	if err != nil {
+		return err
+	}
+	// through slice of implementers
+	implementers := []dbusutil.Implementer{&Group{}, &Session{}}
+	err = service.Export("/com/deepin/Test/Group", implementers...)
+	if err != nil {
+		return err
+	}
+	fakeService{}.Export("/com/deepin/Test/Fake", &Manager{})
+	return service.RequestName("com.deepin.Test")
+}
@@ -0,0 +1,7 @@
+module example.com/exports
+
+go 1.18
+
+require pkg.deepin.io/lib v0.0.0
+
+replace pkg.deepin.io/lib => ./lib
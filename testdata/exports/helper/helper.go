@@ -0,0 +1,8 @@
+package helper
+
+import "pkg.deepin.io/lib/dbusutil"
+
+// export in other package, implementer is only known from callers
+func Export(service *dbusutil.Service, path string, implementer dbusutil.Implementer) error {
+	return service.Export(path, implementer)
+}
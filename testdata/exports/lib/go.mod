@@ -0,0 +1,3 @@
+module pkg.deepin.io/lib
+
+go 1.18
@@ -1,13 +1,19 @@
 package main
 
 import (
-	"bytes"
-	"errors"
-	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
 	"go/types"
-	"log"
-	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	gofile "./writeGoFile"
 )
 
 // find type in info according to name
@@ -21,66 +27,36 @@ func findDef(info types.Info, name string) types.Type {
 	return nil
 }
 
-// format sources to replace mark text
-func FormatImplementers(sources []string) string {
-	commaStr := strings.Join(sources, ",")
-	fmt.Print(commaStr)
-	// implement joint
-	target := "var implementers = []dbusutil.Implementer{" + commaStr + "}"
-	return target
-}
-
-// execute unit test file with go test
-func ExecuteUnitTest(file string) error {
-	log.Println("write to file success")
-	// if update success, execute write DBus xml
-	cmd := exec.Command("go", "test", file, "-count=1", "-v", "-run=TestDBusWriteXml")
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	// run command
-	if err := cmd.Run(); err != nil {
-		log.Println("run command failed, out: ", stdout.String())
-		log.Println("run command failed, err: ", stderr.String())
-		return errors.New(stdout.String())
-	} else {
-		log.Println("run command go test success")
-	}
-	return nil
-}
-
-func ReplaceText(source string, old string, new string) string {
-	return strings.Replace(source, old, new, -1)
-}
-
-// unique string slice
+// unique string slice, elems are kept in order they first appear
 func UniqueSlice(multi []string) []string {
 	// create map
-	uniqueMap := make(map[string]string)
-	// use map to unique slice
-	for _, elem := range multi {
-		// check if elem is empty
-		if elem == "" {
-			continue
-		}
-		// add elem to map
-		uniqueMap[elem] = elem
-	}
+	uniqueMap := make(map[string]bool)
 	// create slice
 	var uniqueSlice []string
-	// add map elem to slice
-	for _, elem := range uniqueMap {
-		// check if elem is empty
-		if elem == "" {
+	for _, elem := range multi {
+		// check if elem is empty or added
+		if elem == "" || uniqueMap[elem] {
 			continue
 		}
+		uniqueMap[elem] = true
 		// add elem to slice
 		uniqueSlice = append(uniqueSlice, elem)
 	}
 	return uniqueSlice
 }
 
+// get defined idents of info in source order, map of info is iterated in random order
+func sortedDefs(info *types.Info) []*ast.Ident {
+	idents := make([]*ast.Ident, 0, len(info.Defs))
+	for ident := range info.Defs {
+		idents = append(idents, ident)
+	}
+	sort.Slice(idents, func(i, j int) bool {
+		return idents[i].Pos() < idents[j].Pos()
+	})
+	return idents
+}
+
 // importer package
 type importer struct{}
 
@@ -88,16 +64,141 @@ func (v *importer) Import(path0 string) (*types.Package, error) {
 	return types.NewPackage(path0, ""), nil
 }
 
-var ModuleStr = `
-replacePackageMark
-import (
-	"pkg.deepin.io/lib/dbusutil"
-	"testing"
-)
-replaceInterfaceMark
-// go test -count=1 -v -run=TestDBusWriteXml
-func TestDBusWriteXml(t *testing.T){
-	for _, value := range implementers {
-		dbusutil.WriteXML(value)
+// convert dir to package pattern, relative dir must start with ./
+func toPackagePattern(dir string) string {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "./") || strings.HasPrefix(dir, "../") {
+		return dir
+	}
+	if dir == "." || dir == ".." {
+		return dir
+	}
+	return "./" + dir
+}
+
+// packages loaded from the same dir, go tool finds module of patterns from dir
+type packageQuery struct {
+	dir      string
+	patterns []string
+}
+
+// group patterns by dir to load them from, patterns in current dir are loaded from it,
+// others are loaded from their own dir, so they can be in other modules
+func groupPatterns(patterns []string) []*packageQuery {
+	var queries []*packageQuery
+	queryMap := make(map[string]*packageQuery)
+	for _, pattern := range patterns {
+		pattern = toPackagePattern(pattern)
+		dir := ""
+		if filepath.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+			dir = pattern
+			pattern = "."
+			if dir == "..." || strings.HasSuffix(dir, "/...") {
+				dir = strings.TrimSuffix(dir, "...")
+				pattern = "./..."
+			}
+			dir = filepath.Clean(dir)
+		}
+		query, ok := queryMap[dir]
+		if !ok {
+			query = &packageQuery{dir: dir}
+			queryMap[dir] = query
+			queries = append(queries, query)
+		}
+		query.patterns = append(query.patterns, pattern)
 	}
-}`
+	return queries
+}
+
+// check if dependencies of package can not be resolved, type errors in package or its
+// dependencies do not need stub importer
+func hasUnresolvedDeps(pkg *packages.Package) bool {
+	return pkg.Types == nil || pkg.TypesInfo == nil || getUnresolvedError(pkg) != nil
+}
+
+// get first error of package or its dependencies which means an import can not be resolved
+func getUnresolvedError(pkg *packages.Package) *packages.Error {
+	var unresolved *packages.Error
+	packages.Visit([]*packages.Package{pkg}, func(p *packages.Package) bool {
+		for index, pkgErr := range p.Errors {
+			if pkgErr.Kind == packages.ListError || strings.Contains(pkgErr.Msg, "could not import") {
+				unresolved = &p.Errors[index]
+				break
+			}
+		}
+		return unresolved == nil
+	}, nil)
+	return unresolved
+}
+
+// report parse errors of package itself, objects in broken files can not be found
+func reportPackageParseErrors(pkg *packages.Package) {
+	for _, pkgErr := range pkg.Errors {
+		if pkgErr.Kind == packages.ParseError {
+			gofile.Reportf(parseErrorPosition(pkgErr.Pos), gofile.SeverityError, gofile.CodeLoadFailed,
+				"%s", pkgErr.Msg)
+		}
+	}
+}
+
+// get position of go/packages error, it is file:line:col or file:line
+func parseErrorPosition(pos string) *gofile.Position {
+	if pos == "" || pos == "-" {
+		return nil
+	}
+	position := token.Position{Filename: pos}
+	// file name may contain ':', line and column are at end
+	for count := 0; count < 2; count++ {
+		index := strings.LastIndex(position.Filename, ":")
+		if index < 0 {
+			break
+		}
+		number, err := strconv.Atoi(position.Filename[index+1:])
+		if err != nil {
+			break
+		}
+		position.Column, position.Line = position.Line, number
+		position.Filename = position.Filename[:index]
+	}
+	return gofile.PositionOf(position)
+}
+
+// report errors of parsing go file
+func reportParseError(err error) {
+	errList, ok := err.(scanner.ErrorList)
+	if !ok {
+		gofile.Reportf(nil, gofile.SeverityError, gofile.CodeLoadFailed, "%v", err)
+		return
+	}
+	for _, e := range errList {
+		gofile.Reportf(gofile.PositionOf(e.Pos), gofile.SeverityError, gofile.CodeLoadFailed, "%s", e.Msg)
+	}
+}
+
+// type check package files with stub importer, all imported types become invalid type
+func checkWithStubImporter(pkg *packages.Package) (*token.FileSet, []*ast.File, *types.Info) {
+	var files []*ast.File
+	fSet := token.NewFileSet()
+	for _, goFile := range pkg.GoFiles {
+		f, err := parser.ParseFile(fSet, goFile, nil, 0)
+		if err != nil {
+			reportParseError(err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	var conf types.Config
+	conf.Error = func(err error) {
+	}
+	conf.Importer = &importer{}
+	_, _ = conf.Check(pkg.PkgPath, fSet, files, info)
+	return fSet, files, info
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"go/types"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
 	C "gopkg.in/check.v1"
 )
 
@@ -11,20 +13,7 @@ func Test(t *testing.T) { C.TestingT(t) }
 type testWrapper struct{}
 
 func init() {
-	C.Suite(testWrapper{})
-}
-
-func (*testWrapper) TestFormatImplementers(c *C.C) {
-	c.Check(FormatImplementers([]string{"&Manager{}", "&ImageBlur{}", "&User{}"}), C.Equals,
-		"var implementers = []dbusutil.Implementer{&Manager{},&ImageBlur{},&User{}}")
-	c.Check(FormatImplementers([]string{}), C.NotNil)
-	c.Check(FormatImplementers([]string{}), C.Equals, "var implementers = []dbusutil.Implementer{}")
-}
-
-func (*testWrapper) TestReplaceText(c *C.C) {
-	c.Check(ReplaceText("This is a test old source", "old", "new"), C.Equals, "This is a test new source")
-	c.Check(ReplaceText("This is a test not exist source", "old", "new"), C.NotNil)
-	c.Check(ReplaceText("This is a test not exist source", "old", "new"), C.Not(C.Equals), "This is a test new source")
+	C.Suite(&testWrapper{})
 }
 
 func (*testWrapper) TestUniqueSlice(c *C.C) {
@@ -32,3 +21,38 @@ func (*testWrapper) TestUniqueSlice(c *C.C) {
 	c.Check(UniqueSlice([]string{"the same", "the same", "the diff"}), C.Not(C.DeepEquals), []string{"the same", "the same", "the diff"})
 	c.Check(UniqueSlice([]string{"the unique", "the diff"}), C.DeepEquals, []string{"the unique", "the diff"})
 }
+
+func (*testWrapper) TestHasUnresolvedDeps(c *C.C) {
+	newPackage := func(path string, errs ...packages.Error) *packages.Package {
+		return &packages.Package{
+			PkgPath:   path,
+			Types:     types.NewPackage(path, "p"),
+			TypesInfo: &types.Info{},
+			Errors:    errs,
+			Imports:   make(map[string]*packages.Package),
+		}
+	}
+	withDep := func(dep *packages.Package) *packages.Package {
+		pkg := newPackage("example.com/p")
+		pkg.Imports[dep.PkgPath] = dep
+		return pkg
+	}
+
+	c.Check(hasUnresolvedDeps(newPackage("example.com/p")), C.Equals, false)
+	c.Check(hasUnresolvedDeps(&packages.Package{PkgPath: "example.com/p"}), C.Equals, true)
+	// type errors do not need stub importer
+	c.Check(hasUnresolvedDeps(newPackage("example.com/p",
+		packages.Error{Msg: "undeclared name: x", Kind: packages.TypeError})), C.Equals, false)
+	c.Check(hasUnresolvedDeps(withDep(newPackage("example.com/dep",
+		packages.Error{Msg: "undeclared name: x", Kind: packages.TypeError}))), C.Equals, false)
+	c.Check(hasUnresolvedDeps(withDep(newPackage("example.com/dep",
+		packages.Error{Msg: "expected ';', found x", Kind: packages.ParseError}))), C.Equals, false)
+
+	c.Check(hasUnresolvedDeps(newPackage("example.com/p",
+		packages.Error{Msg: "no required module provides package example.com/missing", Kind: packages.ListError})),
+		C.Equals, true)
+	dep := newPackage("example.com/dep",
+		packages.Error{Msg: "could not import example.com/missing (no metadata)", Kind: packages.TypeError})
+	c.Check(hasUnresolvedDeps(withDep(dep)), C.Equals, true)
+	c.Check(getUnresolvedError(withDep(dep)), C.Equals, &dep.Errors[0])
+}
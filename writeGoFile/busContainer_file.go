@@ -2,6 +2,8 @@ package writeGoFile
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
 )
 
 type DBusContainer struct {
@@ -14,10 +16,18 @@ type DBusElem struct {
 	DBusPath      string
 	DBusObjName   string
 	DBusInterface string
+	ServiceName   string
+	BusType       string
 
 	// identity
-	DBusConst string
-	DBusInfo  *StructInfo
+	DBusConst    string
+	DBusInfo     *StructInfo
+	DBusPathExpr ast.Expr
+	ExportRecv   ast.Expr
+	// position of export call
+	Position token.Position
+	// end of export call, it is invalid if unknown
+	End token.Position
 }
 
 type StructInfo struct {
@@ -47,9 +57,13 @@ func (container *DBusContainer) AddDBusElem(elem ...*DBusElem) {
 	container.busSlice = append(container.busSlice, elem...)
 }
 
-func (container *DBusContainer) RefreshDBusPath(astFiles []*ast.File) {
+func (container *DBusContainer) RefreshDBusPath(astFiles []*ast.File, info *types.Info) {
 	// refresh DBusPath
 	for _, elem := range container.busSlice {
+		// evaluate path expression with type checker first
+		if elem.DBusPath == "" {
+			elem.DBusPath = GetConstString(info, elem.DBusPathExpr)
+		}
 		// check if DBusPath is empty
 		if elem.DBusPath == "" {
 			for _, astFile := range astFiles {
@@ -61,6 +75,10 @@ func (container *DBusContainer) RefreshDBusPath(astFiles []*ast.File) {
 				}
 			}
 		}
+		// path is built at runtime, keep it as template
+		if elem.DBusPath == "" {
+			elem.DBusPath = GetPathTemplate(info, elem.DBusPathExpr)
+		}
 	}
 }
 
@@ -79,7 +97,7 @@ func (container *DBusContainer) RefreshDBusObj(astFiles []*ast.File) {
 	}
 }
 
-func (container *DBusContainer) RefreshDBusInterface(astFiles []*ast.File) {
+func (container *DBusContainer) RefreshDBusInterface(astFiles []*ast.File, info *types.Info) {
 	for _, elem := range container.busSlice {
 		if elem.DBusObjName == "" {
 			continue
@@ -95,17 +113,19 @@ func (container *DBusContainer) RefreshDBusInterface(astFiles []*ast.File) {
 					if funcName.Name != "GetInterfaceName" {
 						continue
 					}
+					// function without receiver or body, e.g. implemented in assembly
+					if funcDecl.Recv == nil || funcDecl.Body == nil {
+						continue
+					}
 					recvList := funcDecl.Recv.List
 					if len(recvList) == 0 {
 						continue
 					}
 					recvType := recvList[0].Type
-					starExpr, ok := recvType.(*ast.StarExpr)
-					if !ok {
-						continue
+					if starExpr, ok := recvType.(*ast.StarExpr); ok {
+						recvType = starExpr.X
 					}
-					expr := starExpr.X
-					xIdent, ok := expr.(*ast.Ident)
+					xIdent, ok := recvType.(*ast.Ident)
 					if !ok {
 						continue
 					}
@@ -118,6 +138,15 @@ func (container *DBusContainer) RefreshDBusInterface(astFiles []*ast.File) {
 								if len(rlt) == 0 {
 									continue
 								}
+								// evaluate returned constant with type checker first
+								if itf := GetConstString(info, rlt[0]); itf != "" {
+									elem.DBusInterface = itf
+									continue
+								}
+								if bc, ok := rlt[0].(*ast.BasicLit); ok {
+									elem.DBusInterface = unquoteLit(bc)
+									continue
+								}
 								ident, ok := rlt[0].(*ast.Ident)
 								if !ok {
 									continue
@@ -135,3 +164,17 @@ func (container *DBusContainer) RefreshDBusInterface(astFiles []*ast.File) {
 		}
 	}
 }
+
+func (container *DBusContainer) RefreshDBusService(tracer *ServiceTracer) {
+	for _, elem := range container.busSlice {
+		if elem.ServiceName != "" || elem.ExportRecv == nil {
+			continue
+		}
+		service := tracer.GetService(elem.ExportRecv)
+		if service == nil {
+			continue
+		}
+		elem.ServiceName = service.ServiceName
+		elem.BusType = service.BusType
+	}
+}
@@ -0,0 +1,38 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	C "gopkg.in/check.v1"
+)
+
+type containerSuite struct{}
+
+var _ = C.Suite(&containerSuite{})
+
+const interfaceSource = `package exports
+
+type Manager struct{}
+
+type User struct{}
+
+func GetInterfaceName() string { return "com.deepin.Package" }
+
+func (*Manager) GetInterfaceName() string
+
+func (*User) GetInterfaceName() string { return "com.deepin.User" }
+`
+
+// functions without receiver or body are not interface of object
+func (*containerSuite) TestRefreshDBusInterface(c *C.C) {
+	fSet := token.NewFileSet()
+	f, err := parser.ParseFile(fSet, "exports.go", interfaceSource, 0)
+	c.Assert(err, C.IsNil)
+	container := NewDBusContainer()
+	container.AddDBusElem(&DBusElem{DBusObjName: "Manager"}, &DBusElem{DBusObjName: "User"})
+	container.RefreshDBusInterface([]*ast.File{f}, nil)
+	c.Check(container.GetDBusElemByObj("Manager").DBusInterface, C.Equals, "")
+	c.Check(container.GetDBusElemByObj("User").DBusInterface, C.Equals, "com.deepin.User")
+}
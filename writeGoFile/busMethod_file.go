@@ -0,0 +1,103 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+)
+
+// DBusArg is arg of method or signal, type is D-Bus signature of arg
+type DBusArg struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// DBusAnnotation is annotation of interface or member, e.g. org.freedesktop.DBus.Deprecated
+type DBusAnnotation struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// DBusMethod is method of bus object, args are the ones seen on bus
+type DBusMethod struct {
+	Name        string            `json:"name" yaml:"name"`
+	In          []*DBusArg        `json:"in,omitempty" yaml:"in,omitempty"`
+	Out         []*DBusArg        `json:"out,omitempty" yaml:"out,omitempty"`
+	Annotations []*DBusAnnotation `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Position    *Position         `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+// in and out arg names of method
+type argNames struct {
+	in  []string
+	out []string
+}
+
+// create method of go method, names declared in tags of methods field are preferred
+func NewDBusMethod(fSet *token.FileSet, method *types.Func, names *argNames) (*DBusMethod, error) {
+	signature, ok := method.Type().(*types.Signature)
+	if !ok {
+		return nil, fmt.Errorf("type of %s is not signature", method.Name())
+	}
+	if names == nil {
+		names = &argNames{}
+	}
+	params, results := getMethodArgs(signature)
+	in, err := newDBusArgs(params, names.in)
+	if err != nil {
+		return nil, err
+	}
+	out, err := newDBusArgs(results, names.out)
+	if err != nil {
+		return nil, err
+	}
+	return &DBusMethod{
+		Name:     method.Name(),
+		In:       in,
+		Out:      out,
+		Position: NewNameRange(fSet, method),
+	}, nil
+}
+
+// ArgError is error of arg which has no D-Bus signature, member of it is skipped as whole
+type ArgError struct {
+	Arg  *types.Var
+	Name string
+	Err  error
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("arg %s: %v", e.Name, e.Err)
+}
+
+// get position of arg if err is ArgError, position of member is used if not
+func getArgErrorPosition(fSet *token.FileSet, err error, position *Position) *Position {
+	argErr, ok := err.(*ArgError)
+	if !ok {
+		return position
+	}
+	if argPosition := NewNameRange(fSet, argErr.Arg); argPosition != nil {
+		return argPosition
+	}
+	return position
+}
+
+// convert vars to args, names declared in tags are preferred
+func newDBusArgs(vars []*types.Var, names []string) ([]*DBusArg, error) {
+	var args []*DBusArg
+	for index, pVar := range vars {
+		name := pVar.Name()
+		if index < len(names) && names[index] != "" {
+			name = names[index]
+		}
+		sig, err := SignatureOf(pVar.Type())
+		if err != nil {
+			return nil, &ArgError{Arg: pVar, Name: name, Err: err}
+		}
+		args = append(args, &DBusArg{
+			Name: name,
+			Type: sig,
+		})
+	}
+	return args, nil
+}
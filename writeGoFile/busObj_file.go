@@ -1,8 +1,10 @@
 package writeGoFile
 
 import (
+	"go/token"
 	"go/types"
-	"log"
+	"reflect"
+	"strings"
 )
 
 type DBusObject struct {
@@ -12,17 +14,31 @@ type DBusObject struct {
 
 	// export interface
 	serviceName   string
+	busType       string
 	busPath       string
 	interfaceName string
 
 	// Properties
-	properties []*types.Var
+	properties []*DBusProperty
 
 	// method
-	methods []*types.Func
+	methods []*DBusMethod
 
 	//signal
-	signals []*types.Var
+	signals []*DBusSignal
+
+	// annotations of interface, e.g. from introspection xml
+	annotations []*DBusAnnotation
+
+	// go type of object, nil if object is not loaded from source
+	source *ObjectSource
+
+	// names of generated types and dir of generated code set by config, empty if not set
+	fixedTypeName   string
+	fixedObjectName string
+	outputDir       string
+	// order of objects and members set by SortDBusObjects, empty if not sorted
+	order string
 }
 
 func NewDBusObject() *DBusObject {
@@ -30,15 +46,34 @@ func NewDBusObject() *DBusObject {
 		TypeName:      "",
 		ObjectName:    "",
 		serviceName:   "",
+		busType:       "",
 		busPath:       "",
 		interfaceName: "",
-		properties:    []*types.Var{},
-		methods:       []*types.Func{},
-		signals:       []*types.Var{},
+		properties:    []*DBusProperty{},
+		methods:       []*DBusMethod{},
+		signals:       []*DBusSignal{},
 	}
 	return budObject
 }
 
+// create bus object of named type, export info is got from element
+func NewDBusObjectFromElem(fSet *token.FileSet, elem *DBusElem, named *types.Named) *DBusObject {
+	busObject := NewDBusObject()
+	busObject.SetDBusPath(elem.DBusPath)
+	busObject.SetInterfaceName(elem.DBusInterface)
+	busObject.SetServiceName(elem.ServiceName)
+	busObject.SetBusType(elem.BusType)
+	busObject.source = &ObjectSource{
+		Package:     named.Obj().Pkg().Name(),
+		PackagePath: named.Obj().Pkg().Path(),
+		Type:        named.Obj().Name(),
+		Position:    NewNameRange(fSet, named.Obj()),
+		Export:      RangeOf(elem.Position, elem.End),
+	}
+	busObject.SetTypesNamed(fSet, named)
+	return busObject
+}
+
 func (o *DBusObject) SetPackageName(packageName string) {
 	o.ObjectName = packageName
 }
@@ -47,6 +82,18 @@ func (o *DBusObject) SetServiceName(service string) {
 	o.serviceName = service
 }
 
+func (o *DBusObject) GetServiceName() string {
+	return o.serviceName
+}
+
+func (o *DBusObject) SetBusType(busType string) {
+	o.busType = busType
+}
+
+func (o *DBusObject) GetBusType() string {
+	return o.busType
+}
+
 func (o *DBusObject) SetInterfaceName(bus string) {
 	o.interfaceName = bus
 }
@@ -55,31 +102,74 @@ func (o *DBusObject) SetDBusPath(busPath string) {
 	o.busPath = busPath
 }
 
-// set type
-func (o *DBusObject) SetTypesNamed(named *types.Named) {
-	// add properties and signals
+func (o *DBusObject) GetDBusPath() string {
+	return o.busPath
+}
+
+// check if path of object has variable parts, e.g. /com/deepin/daemon/Accounts/User{uid}
+func (o *DBusObject) IsPathTemplate() bool {
+	return IsPathTemplate(o.busPath)
+}
+
+func (o *DBusObject) GetSource() *ObjectSource {
+	return o.source
+}
+
+// set dir of package which declares object
+func (o *DBusObject) SetSourceDir(dir string) {
+	if o.source != nil {
+		o.source.Dir = dir
+	}
+}
+
+// set type, members are converted to D-Bus signatures, positions are got from fSet
+func (o *DBusObject) SetTypesNamed(fSet *token.FileSet, named *types.Named) {
+	// add properties
 	fields, ok := named.Underlying().(*types.Struct)
 	if ok {
 		for tIndex := 0; tIndex < fields.NumFields(); tIndex++ {
 			field := fields.Field(tIndex)
-			// judge type
-			if IsProperty(field) {
-				// if var type is property, add to property
-				o.AddProperty(field)
-			} else if IsSignals(field) {
-				// if var type is signals
-				pointer, ok := field.Type().(*types.Pointer)
-				if !ok {
-					continue
-				}
-				// check if is struct
-				signals, ok := pointer.Elem().(*types.Struct)
-				if !ok {
-					continue
+			// declarations are handled below
+			if IsSignals(field) || IsMethodsDecl(field) {
+				continue
+			}
+			if err := CheckProperty(field, fields.Tag(tIndex)); err != nil {
+				// fields can not be marshaled are dropped, others are not properties on purpose
+				severity, code := SeverityInfo, CodeSkippedField
+				if _, ok := err.(*SignatureError); ok {
+					severity, code = SeverityWarning, CodeUnsupportedType
 				}
-				// add signal
-				for sIndex := 0; sIndex < signals.NumFields(); sIndex++ {
-					o.AddSignal(signals.Field(sIndex))
+				o.report(NewNameRange(fSet, field), severity, code, "skip field %s.%s, reason: %v",
+					named.Obj().Name(), field.Name(), err)
+				continue
+			}
+			// if var type is property, add to property
+			prop, err := NewDBusProperty(fSet, field, fields.Tag(tIndex))
+			if err != nil {
+				o.report(NewNameRange(fSet, field), SeverityWarning, CodeUnsupportedType,
+					"skip property %s, reason: %v", field.Name(), err)
+				continue
+			}
+			o.AddProperty(prop)
+		}
+	}
+
+	// add signals, signals may be declared in embedded struct
+	if field := findDeclField(named, "signals"); field != nil {
+		for _, signal := range o.getSignals(fSet, field) {
+			o.AddSignal(signal)
+		}
+	}
+
+	// record arg names in tags of methods declaration, key is method name
+	methodArgNames := make(map[string]*argNames)
+	if field := findDeclField(named, "methods"); field != nil {
+		if methods := getDeclStruct(field.Type()); methods != nil {
+			for mIndex := 0; mIndex < methods.NumFields(); mIndex++ {
+				tag := reflect.StructTag(methods.Tag(mIndex))
+				methodArgNames[methods.Field(mIndex).Name()] = &argNames{
+					in:  splitArgNames(tag.Get("in")),
+					out: splitArgNames(tag.Get("out")),
 				}
 			}
 		}
@@ -88,35 +178,59 @@ func (o *DBusObject) SetTypesNamed(named *types.Named) {
 	// add methods
 	for mIndex := 0; mIndex < named.NumMethods(); mIndex++ {
 		method := named.Method(mIndex)
-		if IsMethod(method) {
-			o.AddMethod(method)
-		} else if IsInterface(method) {
-			o.interfaceName = ""
+		if !IsMethod(method) {
+			continue
+		}
+		busMethod, err := NewDBusMethod(fSet, method, methodArgNames[method.Name()])
+		if err != nil {
+			o.report(getArgErrorPosition(fSet, err, NewNameRange(fSet, method)), SeverityWarning,
+				CodeUnsupportedType, "skip method %s, reason: %v", method.Name(), err)
+			continue
 		}
+		o.AddMethod(busMethod)
 	}
-	log.Print("end")
 }
 
-func (o *DBusObject) SetMethods(methods []*types.Func) {
+func (o *DBusObject) SetMethods(methods []*DBusMethod) {
 	o.methods = methods
 }
 
-func (o *DBusObject) AddProperty(property *types.Var) {
+func (o *DBusObject) AddProperty(property *DBusProperty) {
 	o.properties = append(o.properties, property)
 }
 
-func (o *DBusObject) GetProperties() []*types.Var {
+func (o *DBusObject) GetProperties() []*DBusProperty {
 	return o.properties
 }
 
-func (o *DBusObject) AddSignal(signal *types.Var) {
+func (o *DBusObject) AddSignal(signal *DBusSignal) {
 	o.signals = append(o.signals, signal)
 }
 
-func (o *DBusObject) GetSignals() []*types.Var {
+func (o *DBusObject) GetSignals() []*DBusSignal {
 	return o.signals
 }
 
-func (o *DBusObject) AddMethod(method *types.Func) {
+func (o *DBusObject) AddMethod(method *DBusMethod) {
 	o.methods = append(o.methods, method)
 }
+
+func (o *DBusObject) GetMethods() []*DBusMethod {
+	return o.methods
+}
+
+func (o *DBusObject) GetInterfaceName() string {
+	return o.interfaceName
+}
+
+// split comma separated arg names in methods tag
+func splitArgNames(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	names := strings.Split(tag, ",")
+	for index := range names {
+		names[index] = strings.TrimSpace(names[index])
+	}
+	return names
+}
@@ -0,0 +1,161 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// functions which format their first arg to string
+// names are lower camel case, same as getPlaceholderName
+var formatFuncMap = map[string]bool{
+	"itoa":        true,
+	"formatInt":   true,
+	"formatUint":  true,
+	"sprint":      true,
+	"quote":       true,
+	"formatFloat": true,
+	"string":      true,
+}
+
+// check if path is a template with variable parts
+func IsPathTemplate(path string) bool {
+	return strings.Contains(path, "{")
+}
+
+// get path template of expression, constant parts are evaluated and variable parts are
+// replaced by {name}, e.g. userPath + uid => /com/deepin/daemon/Accounts/User{uid}
+func GetPathTemplate(info *types.Info, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	// whole expression is constant
+	if value := GetConstString(info, expr); value != "" {
+		return value
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return unquoteLit(e)
+	case *ast.ParenExpr:
+		return GetPathTemplate(info, e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return ""
+		}
+		return GetPathTemplate(info, e.X) + GetPathTemplate(info, e.Y)
+	case *ast.CallExpr:
+		return getCallPathTemplate(info, e)
+	}
+	return "{" + getPlaceholderName(expr) + "}"
+}
+
+// get path template of call, conversions and formatting functions are understood
+func getCallPathTemplate(info *types.Info, callExpr *ast.CallExpr) string {
+	// conversion, dbus.ObjectPath(path)
+	if info != nil && len(callExpr.Args) == 1 {
+		if tv, ok := info.Types[callExpr.Fun]; ok && tv.IsType() {
+			return GetPathTemplate(info, callExpr.Args[0])
+		}
+	}
+	funcName := getPlaceholderName(callExpr.Fun)
+	switch {
+	case funcName == "sprintf" && len(callExpr.Args) > 0:
+		format := GetConstString(info, callExpr.Args[0])
+		if format == "" {
+			if bc, ok := callExpr.Args[0].(*ast.BasicLit); ok {
+				format = unquoteLit(bc)
+			}
+		}
+		if format == "" {
+			break
+		}
+		return formatPathTemplate(info, format, callExpr.Args[1:])
+	case formatFuncMap[funcName] && len(callExpr.Args) > 0:
+		return "{" + getPlaceholderName(callExpr.Args[0]) + "}"
+	}
+	return "{" + funcName + "}"
+}
+
+// replace verbs in format with placeholders of args
+func formatPathTemplate(info *types.Info, format string, args []ast.Expr) string {
+	var buf strings.Builder
+	argIndex := 0
+	for index := 0; index < len(format); index++ {
+		if format[index] != '%' {
+			buf.WriteByte(format[index])
+			continue
+		}
+		// skip flags and width until verb
+		end := index + 1
+		for end < len(format) && strings.IndexByte("+-# 0123456789.", format[end]) >= 0 {
+			end++
+		}
+		if end >= len(format) {
+			break
+		}
+		if format[end] == '%' {
+			buf.WriteByte('%')
+		} else if argIndex < len(args) {
+			arg := args[argIndex]
+			// only strings are evaluated, others are formatted at runtime
+			template := ""
+			if isStringExpr(info, arg) {
+				template = GetPathTemplate(info, arg)
+			}
+			if template == "" {
+				template = "{" + getPlaceholderName(arg) + "}"
+			}
+			buf.WriteString(template)
+			argIndex++
+		}
+		index = end
+	}
+	return buf.String()
+}
+
+// check if expression is string, it is treated as string if type is unknown
+func isStringExpr(info *types.Info, expr ast.Expr) bool {
+	if bc, ok := expr.(*ast.BasicLit); ok {
+		return bc.Kind == token.STRING
+	}
+	if info == nil {
+		return true
+	}
+	tv, ok := info.Types[expr]
+	if !ok || tv.Type == nil {
+		return true
+	}
+	basic, ok := tv.Type.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// get name of placeholder from expression, name is lower camel case
+func getPlaceholderName(expr ast.Expr) string {
+	var name string
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	case *ast.CallExpr:
+		name = getPlaceholderName(e.Fun)
+		// u.GetUid() => uid
+		if strings.HasPrefix(name, "get") && len(name) > 3 {
+			name = name[3:]
+		}
+	case *ast.StarExpr:
+		return getPlaceholderName(e.X)
+	case *ast.ParenExpr:
+		return getPlaceholderName(e.X)
+	case *ast.IndexExpr:
+		return getPlaceholderName(e.X)
+	}
+	if name == "" {
+		return "arg"
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
@@ -0,0 +1,71 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	C "gopkg.in/check.v1"
+)
+
+type pathSuite struct{}
+
+var _ = C.Suite(&pathSuite{})
+
+const pathSource = `package paths
+
+func Sprintf(format string, a ...interface{}) string { return format }
+
+const userPath = "/com/deepin/User"
+
+const defaultUid = 1000
+
+type User struct {
+	uid  int
+	ids  []int
+	name string
+}
+
+func paths(u *User, name string, n int) []string {
+	return []string{
+		Sprintf("%s%d", userPath, u.uid),
+		Sprintf("/com/deepin/%s/%d", name+"s", n+1),
+		Sprintf("/com/deepin/User%d", 1000),
+		Sprintf("/com/deepin/User%d", defaultUid),
+		Sprintf("/com/deepin/User%x_%s", u.ids[0], []byte(u.name)),
+		Sprintf("/com/deepin/%-3d%%/%s", n),
+	}
+}
+`
+
+// args of Sprintf which are not strings are placeholders, even if they are constants
+func (*pathSuite) TestSprintfPathTemplate(c *C.C) {
+	fSet := token.NewFileSet()
+	f, err := parser.ParseFile(fSet, "paths.go", pathSource, 0)
+	c.Assert(err, C.IsNil)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	_, err = (&types.Config{}).Check("paths", fSet, []*ast.File{f}, info)
+	c.Assert(err, C.IsNil)
+
+	var templates []string
+	ast.Inspect(f, func(node ast.Node) bool {
+		if callExpr, ok := node.(*ast.CallExpr); ok {
+			templates = append(templates, GetPathTemplate(info, callExpr))
+			return false
+		}
+		return true
+	})
+	c.Check(templates, C.DeepEquals, []string{
+		"/com/deepin/User{uid}",
+		"/com/deepin/{name}s/{arg}",
+		"/com/deepin/User{arg}",
+		"/com/deepin/User{defaultUid}",
+		"/com/deepin/User{ids}_{arg}",
+		"/com/deepin/{n}%/",
+	})
+}
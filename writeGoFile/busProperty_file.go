@@ -0,0 +1,88 @@
+package writeGoFile
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// property access
+const (
+	AccessRead      = "read"
+	AccessWrite     = "write"
+	AccessReadWrite = "readwrite"
+)
+
+// emit behavior of property, same as org.freedesktop.DBus.Property.EmitsChangedSignal
+const (
+	EmitTrue        = "true"
+	EmitFalse       = "false"
+	EmitInvalidates = "invalidates"
+	EmitConst       = "const"
+)
+
+// annotation name of emit behavior
+const emitsChangedAnnotation = "org.freedesktop.DBus.Property.EmitsChangedSignal"
+
+// DBusProperty is property of bus object, parsed from struct field and its prop tag
+type DBusProperty struct {
+	Name   string `json:"name" yaml:"name"`
+	Type   string `json:"type" yaml:"type"`
+	Access string `json:"access" yaml:"access"`
+	Emit   string `json:"emit" yaml:"emit"`
+	// annotations other than EmitsChangedSignal, which is kept as emit
+	Annotations []*DBusAnnotation `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Position    *Position         `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+// create property of field, tag is like `prop:"access:rw,emit:false"`
+func NewDBusProperty(fSet *token.FileSet, field *types.Var, tag string) (*DBusProperty, error) {
+	sig, err := SignatureOf(field.Type())
+	if err != nil {
+		return nil, err
+	}
+	prop := &DBusProperty{
+		Name:     field.Name(),
+		Type:     sig,
+		Access:   AccessRead,
+		Emit:     EmitTrue,
+		Position: NewNameRange(fSet, field),
+	}
+	propTag := reflect.StructTag(tag).Get("prop")
+	for _, item := range strings.Split(propTag, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.HasPrefix(item, "access:"):
+			switch strings.TrimPrefix(item, "access:") {
+			case "rw", "readwrite":
+				prop.Access = AccessReadWrite
+			case "w", "write":
+				prop.Access = AccessWrite
+			}
+		case strings.HasPrefix(item, "emit:"):
+			switch strings.TrimPrefix(item, "emit:") {
+			case "false":
+				prop.Emit = EmitFalse
+			case "invalidates":
+				prop.Emit = EmitInvalidates
+			case "const":
+				prop.Emit = EmitConst
+			}
+		}
+	}
+	return prop, nil
+}
+
+func (p *DBusProperty) CanRead() bool {
+	return p.Access != AccessWrite
+}
+
+func (p *DBusProperty) CanWrite() bool {
+	return p.Access != AccessRead
+}
+
+// check if PropertiesChanged is emitted when property changed
+func (p *DBusProperty) EmitsChanged() bool {
+	return p.Emit == EmitTrue || p.Emit == EmitInvalidates
+}
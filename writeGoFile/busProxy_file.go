@@ -0,0 +1,174 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// ProxyObject is object at bus path, every interface exported at the path is embedded in it
+type ProxyObject struct {
+	TypeName    string
+	serviceName string
+	busPath     string
+	interfaces  []*DBusObject
+}
+
+func (p *ProxyObject) GetInterfaces() []*DBusObject {
+	return p.interfaces
+}
+
+// check if path of object is built at runtime, path has variable parts or is unknown
+func (p *ProxyObject) IsPathTemplate() bool {
+	return p.busPath == "" || IsPathTemplate(p.busPath)
+}
+
+// group bus objects exported at the same path of the same service into proxy objects,
+// type name of proxy object and object name of every interface are set
+func GroupDBusObjects(objects []*DBusObject) []*ProxyObject {
+	var proxies []*ProxyObject
+	proxyMap := make(map[string]*ProxyObject)
+	for _, object := range objects {
+		// path can not be resolved, can not group with others
+		key := object.serviceName + ":" + object.busPath
+		proxy, ok := proxyMap[key]
+		if !ok || object.busPath == "" {
+			proxy = &ProxyObject{
+				serviceName: object.serviceName,
+				busPath:     object.busPath,
+			}
+			proxyMap[key] = proxy
+			proxies = append(proxies, proxy)
+		}
+		proxy.interfaces = append(proxy.interfaces, object)
+	}
+
+	// names are declared in the same package, they must be unique in all proxy objects
+	usedNames := make(map[string]bool)
+	for _, proxy := range proxies {
+		proxy.TypeName = uniqueName(getProxyTypeName(proxy), usedNames)
+		usedNames[proxy.TypeName] = true
+		// field and accessor of interface are declared in proxy struct, they can not shadow
+		// members promoted from interfaces
+		members := getPromotedNames(proxy)
+		for _, object := range proxy.interfaces {
+			object.TypeName = proxy.TypeName
+			objectName := object.fixedObjectName
+			if objectName == "" {
+				objectName = lowerFirst(toIdentifier(lastElem(object.interfaceName, ".")))
+			}
+			object.ObjectName = uniqueObjectName(objectName, usedNames, members)
+			usedNames[object.ObjectName] = true
+		}
+	}
+	return proxies
+}
+
+// get names of members promoted to proxy struct from its embedded fields
+func getPromotedNames(proxy *ProxyObject) map[string]bool {
+	names := map[string]bool{
+		"Object":            true,
+		"GetObject_":        true,
+		"GetInterfaceName_": true,
+	}
+	for _, object := range proxy.interfaces {
+		for _, method := range object.methods {
+			name := strings.Title(method.Name)
+			names[name] = true
+			names["Go"+name] = true
+			names["Store"+name] = true
+		}
+		for _, prop := range object.properties {
+			names[prop.Name] = true
+		}
+		for _, signal := range object.signals {
+			names["Connect"+strings.Title(signal.Name)] = true
+		}
+	}
+	return names
+}
+
+// get unique object name, neither field nor accessor named by it is one of members,
+// e.g. accessor Group() hides method Group of another interface in the same proxy object
+func uniqueObjectName(name string, used map[string]bool, members map[string]bool) string {
+	if name == "" {
+		name = "object"
+	}
+	result := uniqueName(name, used)
+	for index := 1; members[result] || members[upperFirst(result)]; index++ {
+		result = uniqueName(fmt.Sprintf("%s%d", name, index), used)
+	}
+	return result
+}
+
+// get type name of proxy object from last element of path,
+// e.g. /com/deepin/daemon/Accounts/User{uid} => User, name set by config is used first
+func getProxyTypeName(proxy *ProxyObject) string {
+	for _, object := range proxy.interfaces {
+		if object.fixedTypeName != "" {
+			return object.fixedTypeName
+		}
+	}
+	name := toIdentifier(lastElem(proxy.busPath, "/"))
+	if name == "" && len(proxy.interfaces) > 0 {
+		name = toIdentifier(lastElem(proxy.interfaces[0].interfaceName, "."))
+	}
+	if name == "" {
+		name = "Object"
+	}
+	return upperFirst(name)
+}
+
+// get last element of name split by sep
+func lastElem(name string, sep string) string {
+	return name[strings.LastIndex(name, sep)+1:]
+}
+
+// remove placeholders and chars can not be used in identifier
+func toIdentifier(name string) string {
+	var buf strings.Builder
+	inPlaceholder := false
+	for _, r := range name {
+		switch {
+		case r == '{':
+			inPlaceholder = true
+		case r == '}':
+			inPlaceholder = false
+		case inPlaceholder:
+		case unicode.IsLetter(r) || r == '_' || (unicode.IsDigit(r) && buf.Len() > 0):
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// add number suffix if name is used
+func uniqueName(name string, used map[string]bool) string {
+	if name == "" {
+		name = "object"
+	}
+	// lower case name may be keyword, e.g. com.deepin.Type => type
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	result := name
+	for index := 1; used[result]; index++ {
+		result = fmt.Sprintf("%s%d", name, index)
+	}
+	return result
+}
+
+func upperFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
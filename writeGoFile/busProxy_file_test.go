@@ -0,0 +1,30 @@
+package writeGoFile
+
+import (
+	C "gopkg.in/check.v1"
+)
+
+type proxySuite struct{}
+
+var _ = C.Suite(&proxySuite{})
+
+func (*proxySuite) TestGroupDBusObjects(c *C.C) {
+	user := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test.User")
+	user.methods = []*DBusMethod{{Name: "Group"}, {Name: "storeUser"}}
+	group := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test.Group")
+	group.signals = []*DBusSignal{{Name: "Changed"}}
+	changed := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test.ConnectChanged")
+	object := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test.Object")
+	object.properties = []*DBusProperty{{Name: "object1"}}
+
+	proxies := GroupDBusObjects([]*DBusObject{user, group, changed, object})
+	c.Assert(proxies, C.HasLen, 1)
+	c.Check(proxies[0].TypeName, C.Equals, "Test")
+	c.Check(user.ObjectName, C.Equals, "user")
+	// accessors can not hide Group, StoreUser, ConnectChanged and embedded proxy.Object
+	c.Check(group.ObjectName, C.Equals, "group1")
+	c.Check(changed.ObjectName, C.Equals, "connectChanged1")
+	// field can not hide property object1
+	c.Check(object.ObjectName, C.Equals, "object2")
+
+}
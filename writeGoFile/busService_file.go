@@ -0,0 +1,281 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// bus type of service
+const (
+	SessionBus = "session"
+	SystemBus  = "system"
+)
+
+// import path of dbusutil, bus objects are exported by its Service
+const DBusUtilPath = "pkg.deepin.io/lib/dbusutil"
+
+// functions which create service or connection on certain bus
+var busCreatorMap = map[string]string{
+	"NewSessionService": SessionBus,
+	"NewSystemService":  SystemBus,
+	"SessionBus":        SessionBus,
+	"SystemBus":         SystemBus,
+	"ConnectSessionBus": SessionBus,
+	"ConnectSystemBus":  SystemBus,
+}
+
+// check if type is dbusutil.Service or pointer of it
+func IsServiceType(ty types.Type) bool {
+	if pointer, ok := ty.(*types.Pointer); ok {
+		ty = pointer.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == "Service" && named.Obj().Pkg().Path() == DBusUtilPath
+}
+
+// get bus type of service or connection created by function, empty if it does not create one
+func GetCreatorBusType(funcName string) string {
+	return busCreatorMap[funcName]
+}
+
+// DBusService record well-known name and bus type of dbusutil.Service
+type DBusService struct {
+	ServiceName string
+	BusType     string
+}
+
+// ServiceTracer trace dbusutil.Service values through assignments, struct fields and call args
+type ServiceTracer struct {
+	info *types.Info
+	// union find of objects hold the same service
+	parent map[types.Object]types.Object
+	// services of root objects
+	services map[types.Object]*DBusService
+	// functions declared in traced files, only params of them are linked to call args
+	funcs map[types.Object]bool
+}
+
+func NewServiceTracer(info *types.Info) *ServiceTracer {
+	return &ServiceTracer{
+		info:     info,
+		parent:   make(map[types.Object]types.Object),
+		services: make(map[types.Object]*DBusService),
+		funcs:    make(map[types.Object]bool),
+	}
+}
+
+// trace service in files
+func (t *ServiceTracer) Trace(files []*ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				if obj := t.info.Defs[funcDecl.Name]; obj != nil {
+					t.funcs[obj] = true
+				}
+			}
+		}
+	}
+	// link objects first, so service info is merged to root
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) {
+					for index := range n.Lhs {
+						t.union(t.exprObject(n.Lhs[index]), t.exprObject(n.Rhs[index]))
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for index := range n.Names {
+						t.union(t.exprObject(n.Names[index]), t.exprObject(n.Values[index]))
+					}
+				}
+			case *ast.KeyValueExpr:
+				// struct composite literal, key is field
+				t.union(t.exprObject(n.Key), t.exprObject(n.Value))
+			case *ast.CallExpr:
+				t.linkCallArgs(n)
+			}
+			return true
+		})
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				// service, err := dbusutil.NewSessionService()
+				if len(n.Rhs) != 1 || len(n.Lhs) == 0 {
+					return true
+				}
+				t.recordCreator(n.Lhs[0], n.Rhs[0])
+			case *ast.ValueSpec:
+				if len(n.Values) != 1 || len(n.Names) == 0 {
+					return true
+				}
+				t.recordCreator(n.Names[0], n.Values[0])
+			case *ast.CallExpr:
+				// service.RequestName(dbusServiceName)
+				selector, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || selector.Sel.Name != "RequestName" || len(n.Args) != 1 {
+					return true
+				}
+				name := GetConstString(t.info, n.Args[0])
+				if name == "" {
+					return true
+				}
+				service := t.service(t.exprObject(selector.X))
+				if service != nil && service.ServiceName == "" {
+					service.ServiceName = name
+				}
+			}
+			return true
+		})
+	}
+}
+
+// get service which expression holds, return nil if can not be traced
+func (t *ServiceTracer) GetService(expr ast.Expr) *DBusService {
+	obj := t.exprObject(expr)
+	if obj == nil {
+		return nil
+	}
+	return t.services[t.find(obj)]
+}
+
+// record bus type if value is created by bus creator
+func (t *ServiceTracer) recordCreator(lhs ast.Expr, rhs ast.Expr) {
+	callExpr, ok := rhs.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	var funcName string
+	switch fun := callExpr.Fun.(type) {
+	case *ast.SelectorExpr:
+		funcName = fun.Sel.Name
+	case *ast.Ident:
+		funcName = fun.Name
+	}
+	service := t.service(t.exprObject(lhs))
+	if service == nil {
+		return
+	}
+	if busType, ok := busCreatorMap[funcName]; ok {
+		service.BusType = busType
+		return
+	}
+	// dbusutil.NewService(conn), service is on the bus of conn
+	if funcName == "NewService" && len(callExpr.Args) == 1 {
+		t.union(t.exprObject(lhs), t.exprObject(callExpr.Args[0]))
+	}
+}
+
+// link call args to params of callee declared in traced files, only params which are
+// service are linked, values passed to the same function elsewhere are not mixed up
+func (t *ServiceTracer) linkCallArgs(callExpr *ast.CallExpr) {
+	var ident *ast.Ident
+	switch fun := callExpr.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return
+	}
+	fn, ok := t.info.Uses[ident].(*types.Func)
+	if !ok || !t.funcs[fn] {
+		return
+	}
+	signature, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return
+	}
+	params := signature.Params()
+	for index, arg := range callExpr.Args {
+		if index >= params.Len() {
+			break
+		}
+		// type is invalid if dependencies are checked with stub importer
+		paramType := params.At(index).Type()
+		if !IsServiceType(paramType) && paramType != types.Typ[types.Invalid] {
+			continue
+		}
+		t.union(params.At(index), t.exprObject(arg))
+	}
+}
+
+// get object which expression refers to
+func (t *ServiceTracer) exprObject(expr ast.Expr) types.Object {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if obj := t.info.Defs[e]; obj != nil {
+			return obj
+		}
+		return t.info.Uses[e]
+	case *ast.SelectorExpr:
+		return t.info.Uses[e.Sel]
+	case *ast.UnaryExpr:
+		return t.exprObject(e.X)
+	case *ast.StarExpr:
+		return t.exprObject(e.X)
+	case *ast.ParenExpr:
+		return t.exprObject(e.X)
+	}
+	return nil
+}
+
+// get or create service of object
+func (t *ServiceTracer) service(obj types.Object) *DBusService {
+	if obj == nil {
+		return nil
+	}
+	root := t.find(obj)
+	service, ok := t.services[root]
+	if !ok {
+		service = &DBusService{}
+		t.services[root] = service
+	}
+	return service
+}
+
+func (t *ServiceTracer) find(obj types.Object) types.Object {
+	for {
+		parent, ok := t.parent[obj]
+		if !ok || parent == obj {
+			return obj
+		}
+		obj = parent
+	}
+}
+
+// merge objects, service info of both is kept
+func (t *ServiceTracer) union(a, b types.Object) {
+	if a == nil || b == nil {
+		return
+	}
+	rootA, rootB := t.find(a), t.find(b)
+	if rootA == rootB {
+		return
+	}
+	t.parent[rootB] = rootA
+	serviceB, ok := t.services[rootB]
+	if !ok {
+		return
+	}
+	delete(t.services, rootB)
+	serviceA, ok := t.services[rootA]
+	if !ok {
+		t.services[rootA] = serviceB
+		return
+	}
+	if serviceA.ServiceName == "" {
+		serviceA.ServiceName = serviceB.ServiceName
+	}
+	if serviceA.BusType == "" {
+		serviceA.BusType = serviceB.BusType
+	}
+}
@@ -0,0 +1,98 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	C "gopkg.in/check.v1"
+)
+
+type serviceSuite struct{}
+
+var _ = C.Suite(&serviceSuite{})
+
+const tracerSource = `package tracer
+
+import (
+	"github.com/godbus/dbus"
+	"pkg.deepin.io/lib/dbusutil"
+)
+
+const dbusServiceName = "com.deepin.Test"
+
+type Manager struct {
+	service *dbusutil.Service
+}
+
+type Object struct{}
+
+func (*Object) GetInterfaceName() string { return "com.deepin.Test.Object" }
+
+func SystemBus() (*dbus.Conn, error) { return nil, nil }
+
+func newManager(s *dbusutil.Service) *Manager {
+	return &Manager{service: s}
+}
+
+func (m *Manager) export() {
+	m.service.Export("/com/deepin/Test/Manager", &Object{})
+}
+
+func start() {
+	service, _ := dbusutil.NewSessionService()
+	m := newManager(service)
+	m.export()
+	// name is requested after service is passed around
+	service.RequestName(dbusServiceName)
+
+	conn, _ := SystemBus()
+	other := dbusutil.NewService(conn)
+	other.Export("/com/deepin/Test/Other", &Object{})
+
+	var unknown *dbusutil.Service
+	unknown.Export("/com/deepin/Test/Unknown", &Object{})
+}
+
+func printAll(values ...interface{}) {}
+
+// services passed to the same function which does not take service are not mixed up
+func startTwo() {
+	a, _ := dbusutil.NewSessionService()
+	a.RequestName("com.deepin.A")
+	b, _ := dbusutil.NewSystemService()
+	printAll(a, b)
+	b.Export("/com/deepin/B", &Object{})
+}
+`
+
+// service is traced through call args, struct fields and the conn of NewService
+func (*serviceSuite) TestServiceTracer(c *C.C) {
+	fSet := token.NewFileSet()
+	imp := loadStubs(c, fSet)
+	f, err := parser.ParseFile(fSet, "tracer.go", tracerSource, 0)
+	c.Assert(err, C.IsNil)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	_, err = (&types.Config{Importer: imp}).Check("tracer", fSet, []*ast.File{f}, info)
+	c.Assert(err, C.IsNil)
+
+	tracer := NewServiceTracer(info)
+	tracer.Trace([]*ast.File{f})
+	var services []*DBusService
+	ast.Inspect(f, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok && selector.Sel.Name == "Export" {
+			services = append(services, tracer.GetService(selector.X))
+		}
+		return true
+	})
+	c.Assert(services, C.HasLen, 4)
+	c.Check(services[0], C.DeepEquals, &DBusService{ServiceName: "com.deepin.Test", BusType: SessionBus})
+	c.Check(services[1], C.DeepEquals, &DBusService{BusType: SystemBus})
+	c.Check(services[2], C.IsNil)
+	c.Check(services[3], C.DeepEquals, &DBusService{BusType: SystemBus})
+}
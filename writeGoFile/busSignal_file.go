@@ -0,0 +1,74 @@
+package writeGoFile
+
+import (
+	"go/token"
+	"go/types"
+)
+
+// DBusSignal is signal of bus object, declared as field of signals struct
+type DBusSignal struct {
+	Name        string            `json:"name" yaml:"name"`
+	Args        []*DBusArg        `json:"args,omitempty" yaml:"args,omitempty"`
+	Annotations []*DBusAnnotation `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Position    *Position         `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+// create signal of field in signals struct, args are fields of its struct type
+func NewDBusSignal(fSet *token.FileSet, field *types.Var) (*DBusSignal, error) {
+	signal := &DBusSignal{
+		Name:     field.Name(),
+		Position: NewNameRange(fSet, field),
+	}
+	args := getDeclStruct(field.Type())
+	if args == nil {
+		return signal, nil
+	}
+	var vars []*types.Var
+	for aIndex := 0; aIndex < args.NumFields(); aIndex++ {
+		vars = append(vars, args.Field(aIndex))
+	}
+	var err error
+	signal.Args, err = newDBusArgs(vars, nil)
+	if err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// get signals declared in signals field, signals can not be marshaled are skipped
+func (o *DBusObject) getSignals(fSet *token.FileSet, field *types.Var) []*DBusSignal {
+	signals := getDeclStruct(field.Type())
+	if signals == nil {
+		return nil
+	}
+	var result []*DBusSignal
+	for sIndex := 0; sIndex < signals.NumFields(); sIndex++ {
+		signal, err := NewDBusSignal(fSet, signals.Field(sIndex))
+		if err != nil {
+			o.report(getArgErrorPosition(fSet, err, NewNameRange(fSet, signals.Field(sIndex))),
+				SeverityWarning, CodeUnsupportedType, "skip signal %s, reason: %v", signals.Field(sIndex).Name(), err)
+			continue
+		}
+		result = append(result, signal)
+	}
+	return result
+}
+
+// get struct of declaration, it may be pointer or value of anonymous or named struct
+func getDeclStruct(ty types.Type) *types.Struct {
+	if pointer, ok := ty.(*types.Pointer); ok {
+		ty = pointer.Elem()
+	}
+	decl, _ := ty.Underlying().(*types.Struct)
+	return decl
+}
+
+// find declaration field of name, fields promoted from embedded structs are found as well
+func findDeclField(named *types.Named, name string) *types.Var {
+	obj, _, _ := types.LookupFieldOrMethod(named, true, named.Obj().Pkg(), name)
+	field, ok := obj.(*types.Var)
+	if !ok || !field.IsField() {
+		return nil
+	}
+	return field
+}
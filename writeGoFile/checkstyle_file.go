@@ -0,0 +1,73 @@
+package writeGoFile
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// checkstyle report, format is the same as checkstyle xml formatter
+type checkstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// version of checkstyle format, most tools read this version
+const checkstyleVersion = "4.3"
+
+// write diagnostics as checkstyle xml, diagnostics are grouped by file in order files appear,
+// source of error is reason code, diagnostics without position are left out as every error
+// must be in a file
+func writeCheckstyle(w io.Writer, diagnostics []*Diagnostic) error {
+	report := &checkstyleReport{
+		Version: checkstyleVersion,
+	}
+	fileMap := make(map[string]*checkstyleFile)
+	columns := newColumnConverter()
+	for _, d := range diagnostics {
+		if d.Position == nil {
+			continue
+		}
+		name := d.Position.File
+		cError := &checkstyleError{
+			Line:     d.Position.Line,
+			Column:   columns.convert(name, d.Position.Line, d.Position.Column),
+			Severity: d.Severity,
+			Message:  d.describe(),
+			Source:   "deepinAutoWrite." + d.Code,
+		}
+		file, ok := fileMap[name]
+		if !ok {
+			file = &checkstyleFile{Name: name}
+			fileMap[name] = file
+			report.Files = append(report.Files, file)
+		}
+		file.Errors = append(file.Errors, cError)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,244 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is project config, it filters bus objects found and fixes them before generating,
+// see docs/config.md
+type Config struct {
+	// globs of go package paths, objects declared in packages not matched are dropped,
+	// all packages are included if empty
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	// globs of go package paths, objects declared in packages matched are dropped
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// fixes of interfaces, every fix matched is applied in order
+	Interfaces []*InterfaceConfig `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+}
+
+// InterfaceConfig fixes interfaces matched by name or go type
+type InterfaceConfig struct {
+	// glob of interface name
+	Match string `json:"match,omitempty" yaml:"match,omitempty"`
+	// glob of go type which implements interface, e.g. pkg.deepin.io/dde/daemon/accounts.User
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// force interface name, for interfaces which name can not be found in source
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"`
+	// name of generated proxy object type of path interface is exported at
+	TypeName string `json:"typeName,omitempty" yaml:"typeName,omitempty"`
+	// name of generated interface type embedded in proxy object
+	ObjectName string `json:"objectName,omitempty" yaml:"objectName,omitempty"`
+	// globs of methods not to generate
+	SkipMethods []string `json:"skipMethods,omitempty" yaml:"skipMethods,omitempty"`
+	// D-Bus signatures of properties by name, they replace signatures found
+	Properties map[string]string `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// dir of generated go code relative to -outDir, instead of dir of service
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// load config from file, format is chosen by extension same as model,
+// unknown fields are rejected so misspelled options are not ignored
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if isYamlFile(filename) {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		// empty file is empty config
+		if err == io.EOF {
+			err = nil
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s failed, err: %v", filename, err)
+	}
+	err = config.Check()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s, err: %v", filename, err)
+	}
+	return config, nil
+}
+
+// check if globs and fixes of config are valid
+func (c *Config) Check() error {
+	for _, glob := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("package glob %q: %v", glob, err)
+		}
+	}
+	for index, itf := range c.Interfaces {
+		err := itf.check()
+		if err != nil {
+			return fmt.Errorf("interfaces[%d]: %v", index, err)
+		}
+	}
+	return nil
+}
+
+func (ic *InterfaceConfig) check() error {
+	if ic.Match == "" && ic.Source == "" {
+		return errors.New("match or source should be set")
+	}
+	for _, glob := range append([]string{ic.Match, ic.Source}, ic.SkipMethods...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("glob %q: %v", glob, err)
+		}
+	}
+	if ic.Interface != "" {
+		if err := checkInterfaceName(ic.Interface); err != nil {
+			return fmt.Errorf("interface: %v", err)
+		}
+	}
+	for _, name := range []string{ic.TypeName, ic.ObjectName} {
+		if name != "" && toIdentifier(name) != name {
+			return fmt.Errorf("%q is not go identifier", name)
+		}
+	}
+	// accessor of interface is object name in upper case, it can not be the same as field
+	if token.IsExported(ic.ObjectName) {
+		return fmt.Errorf("objectName %q should not be exported", ic.ObjectName)
+	}
+	for name, sig := range ic.Properties {
+		if _, err := GoTypeOf(sig); err != nil {
+			return fmt.Errorf("property %s: %v", name, err)
+		}
+	}
+	output := filepath.ToSlash(filepath.Clean(ic.Output))
+	if filepath.IsAbs(ic.Output) || output == ".." || strings.HasPrefix(output, "../") {
+		return fmt.Errorf("output %q should be relative dir in -outDir", ic.Output)
+	}
+	return nil
+}
+
+// drop objects of packages filtered out and apply fixes of interfaces, objects are kept in order
+func (c *Config) Apply(objects []*DBusObject) []*DBusObject {
+	var result []*DBusObject
+	for _, object := range objects {
+		if !c.isIncluded(object) {
+			continue
+		}
+		for _, itf := range c.Interfaces {
+			if itf.matches(object) {
+				itf.apply(object)
+			}
+		}
+		result = append(result, object)
+	}
+	return result
+}
+
+// check if package of object is included, objects not loaded from source are always included
+func (c *Config) isIncluded(object *DBusObject) bool {
+	if object.source == nil {
+		return true
+	}
+	pkgPath := object.source.PackagePath
+	if len(c.Include) > 0 && !matchPackage(c.Include, pkgPath) {
+		return false
+	}
+	return !matchPackage(c.Exclude, pkgPath)
+}
+
+// check if package path is matched by any glob, glob ends with /... also matches sub packages
+func matchPackage(globs []string, pkgPath string) bool {
+	for _, glob := range globs {
+		if strings.HasSuffix(glob, "/...") {
+			prefix := strings.TrimSuffix(glob, "/...")
+			if ok, _ := path.Match(prefix, pkgPath); ok {
+				return true
+			}
+			for dir := path.Dir(pkgPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+				if ok, _ := path.Match(prefix, dir); ok {
+					return true
+				}
+			}
+			continue
+		}
+		if ok, _ := path.Match(glob, pkgPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// check if fix is for object, both globs must match if both are set
+func (ic *InterfaceConfig) matches(object *DBusObject) bool {
+	if ic.Match != "" {
+		// '.' is not special in path globs, so interface names are matched as they are
+		if ok, _ := path.Match(ic.Match, object.interfaceName); !ok {
+			return false
+		}
+	}
+	if ic.Source != "" {
+		if object.source == nil {
+			return false
+		}
+		sourceType := object.source.PackagePath + "." + object.source.Type
+		if ok, _ := path.Match(ic.Source, sourceType); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (ic *InterfaceConfig) apply(object *DBusObject) {
+	if ic.Interface != "" {
+		object.interfaceName = ic.Interface
+	}
+	if ic.TypeName != "" {
+		object.fixedTypeName = ic.TypeName
+	}
+	if ic.ObjectName != "" {
+		object.fixedObjectName = ic.ObjectName
+	}
+	if ic.Output != "" {
+		object.outputDir = filepath.Clean(ic.Output)
+	}
+	if len(ic.SkipMethods) > 0 {
+		var methods []*DBusMethod
+		for _, method := range object.methods {
+			if matchAny(ic.SkipMethods, method.Name) {
+				object.report(method.Position, SeverityInfo, CodeSkippedByConfig,
+					"skip method %s, reason: it is skipped by config", method.Name)
+				continue
+			}
+			methods = append(methods, method)
+		}
+		object.methods = methods
+	}
+	for _, prop := range object.properties {
+		if sig, ok := ic.Properties[prop.Name]; ok {
+			prop.Type = sig
+		}
+	}
+}
+
+// check if name is matched by any glob
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	C "gopkg.in/check.v1"
+)
+
+type configSuite struct{}
+
+var _ = C.Suite(&configSuite{})
+
+const testConfig = `
+interfaces:
+  - match: com.deepin.daemon.Accounts
+    typeName: AccountsManager
+    objectName: manager
+    skipMethods: [Get*, DeleteUser]
+    properties:
+      Limits: a{ss}
+  - source: accounts.User
+    interface: com.deepin.daemon.Accounts.UserV2
+    output: users
+`
+
+func writeConfig(c *C.C, name string, content string) string {
+	filename := filepath.Join(c.MkDir(), name)
+	c.Assert(os.WriteFile(filename, []byte(content), 0644), C.IsNil)
+	return filename
+}
+
+func (*configSuite) TestConfigApply(c *C.C) {
+	config, err := LoadConfig(writeConfig(c, "config.yaml", testConfig))
+	c.Assert(err, C.IsNil)
+	fSet := token.NewFileSet()
+	imp := loadStubs(c, fSet)
+	_, objects := loadObjects(c, fSet, imp, filepath.Join("testdata", "accounts"))
+	objects = config.Apply(objects)
+
+	outDir := c.MkDir()
+	filenames, err := SaveProxyFiles(DiskOutput{}, outDir, objects)
+	c.Assert(err, C.IsNil)
+	c.Assert(filenames, C.DeepEquals, []string{
+		filepath.Join(outDir, "com.deepin.daemon.accounts", "auto.go"),
+		filepath.Join(outDir, "users", "auto.go"),
+	})
+
+	accounts, err := os.ReadFile(filenames[0])
+	c.Assert(err, C.IsNil)
+	c.Check(string(accounts), C.Matches, `(?s).*type AccountsManager struct \{\n\tmanager // interface com.deepin.daemon.Accounts\n.*`)
+	c.Check(string(accounts), C.Matches, `(?s).*func \(v \*manager\) CreateUser\(.*`)
+	c.Check(string(accounts), C.Not(C.Matches), `(?s).*(GetPoint|DeleteUser).*`)
+	c.Check(string(accounts), C.Matches, `(?s).*func \(v \*manager\) Limits\(\) PropManagerLimits .*`)
+	c.Check(string(accounts), C.Matches, `(?s).*func \(p PropManagerLimits\) Get\(flags dbus.Flags\) \(value map\[string\]string, err error\).*`)
+
+	users, err := os.ReadFile(filenames[1])
+	c.Assert(err, C.IsNil)
+	c.Check(string(users), C.Matches, `(?s).*\npackage users\n.*`)
+	c.Check(string(users), C.Matches, `(?s).*return "com.deepin.daemon.Accounts.UserV2".*`)
+
+	// names set by config do not break generated code
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(fSet, filename, nil, 0)
+		c.Assert(err, C.IsNil)
+		_, err = (&types.Config{Importer: imp}).Check(f.Name.Name, fSet, []*ast.File{f}, nil)
+		c.Check(err, C.IsNil, C.Commentf("type check %s", filename))
+	}
+
+	// xml and config.json are beside go proxies they describe
+	filenames, err = SaveFactoryFiles(DiskOutput{}, outDir, objects)
+	c.Assert(err, C.IsNil)
+	c.Assert(filenames, C.HasLen, 4)
+	c.Check(filenames[1], C.Equals, filepath.Join(outDir, "com.deepin.daemon.accounts", "config.json"))
+	c.Check(filenames[2], C.Matches, regexp.QuoteMeta(filepath.Join(outDir, "users"))+`/\w+\.xml`)
+	c.Check(filenames[3], C.Equals, filepath.Join(outDir, "users", "config.json"))
+}
+
+func (*configSuite) TestConfigFilter(c *C.C) {
+	var objects []*DBusObject
+	for _, pkgPath := range []string{"pkg.deepin.io/dde/daemon/accounts", "pkg.deepin.io/dde/daemon/accounts/users",
+		"pkg.deepin.io/dde/daemon/audio", "pkg.deepin.io/dde/session"} {
+		object := newTestObject("", "", "")
+		object.source.PackagePath = pkgPath
+		objects = append(objects, object)
+	}
+	// objects without source are kept
+	objects = append(objects, NewDBusObject())
+	config := &Config{
+		Include: []string{"pkg.deepin.io/dde/daemon/..."},
+		Exclude: []string{"*/*/*/audio", "pkg.deepin.io/dde/daemon/accounts/*"},
+	}
+	c.Check(config.Apply(objects), C.DeepEquals, []*DBusObject{objects[0], objects[4]})
+}
+
+func (*configSuite) TestConfigInvalid(c *C.C) {
+	_, err := LoadConfig(writeConfig(c, "config.json", `{"interfaces": [{"match": "a.b", "skipMethod": ["Get"]}]}`))
+	c.Check(err, C.ErrorMatches, `parse config .*unknown field "skipMethod".*`)
+
+	_, err = LoadConfig(writeConfig(c, "config.yaml", "interfaces:\n  - typeName: Manager\n"))
+	c.Check(err, C.ErrorMatches, `invalid config .*interfaces\[0\]: match or source should be set`)
+
+	_, err = LoadConfig(writeConfig(c, "config.yaml", "interfaces:\n  - match: a.b\n    properties: {Name: z}\n"))
+	c.Check(err, C.ErrorMatches, `invalid config .*interfaces\[0\]: property Name: .*`)
+
+	_, err = LoadConfig(writeConfig(c, "config.yaml", "interfaces:\n  - match: a.b\n    output: ../proxy\n"))
+	c.Check(err, C.ErrorMatches, `invalid config .*should be relative dir in -outDir`)
+
+	_, err = LoadConfig(writeConfig(c, "config.yaml", "interfaces:\n  - match: a.b\n    objectName: Manager\n"))
+	c.Check(err, C.ErrorMatches, `invalid config .*objectName "Manager" should not be exported`)
+}
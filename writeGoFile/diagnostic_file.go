@@ -0,0 +1,248 @@
+package writeGoFile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// severity of diagnostic
+const (
+	// api can not be found or is wrong, e.g. invalid names
+	SeverityError = "error"
+	// part of api is dropped or can not be resolved, generated code may miss it
+	SeverityWarning = "warning"
+	// something is skipped on purpose, e.g. unexported fields
+	SeverityInfo = "info"
+)
+
+// reason codes of diagnostics, they are stable so tools can filter by them,
+// see CodeDescriptions
+const (
+	CodeLoadFailed          = "load-failed"
+	CodeStubImporter        = "stub-importer"
+	CodeNoCallGraph         = "no-call-graph"
+	CodeNoObjects           = "no-objects"
+	CodeNoExport            = "no-export"
+	CodeUnresolvedExport    = "unresolved-export"
+	CodeUnknownService      = "unknown-service"
+	CodeUnresolvedPath      = "unresolved-path"
+	CodeUnresolvedInterface = "unresolved-interface"
+	CodeSkippedField        = "skipped-field"
+	CodeUnsupportedType     = "unsupported-type"
+	CodeSkippedByConfig     = "skipped-by-config"
+	CodeInvalidProperty     = "invalid-property"
+	CodeInvalidName         = "invalid-name"
+	CodeDuplicateInterface  = "duplicate-interface"
+)
+
+// descriptions of reason codes
+var CodeDescriptions = map[string]string{
+	CodeLoadFailed:          "go file can not be parsed, objects declared in it are not found",
+	CodeStubImporter:        "dependencies of package can not be resolved, package is checked with stub importer",
+	CodeNoCallGraph:         "call graph analysis can not run, only exports in the same package are found",
+	CodeNoObjects:           "no bus object is found in packages",
+	CodeNoExport:            "type implements interface but is never exported",
+	CodeUnresolvedExport:    "implementer of export call can not be resolved",
+	CodeUnknownService:      "service name or bus type of export can not be determined",
+	CodeUnresolvedPath:      "path of export can not be determined",
+	CodeUnresolvedInterface: "interface name of export can not be determined",
+	CodeSkippedField:        "field is not property, e.g. it is unexported or embedded",
+	CodeUnsupportedType:     "member is dropped as its type can not be marshaled to D-Bus",
+	CodeSkippedByConfig:     "member is dropped by config",
+	CodeInvalidProperty:     "access or emit behavior of property in xml is invalid",
+	CodeInvalidName:         "service, bus type, path, interface or member name is invalid",
+	CodeDuplicateInterface:  "interface is exported more than once at the same path",
+}
+
+// Diagnostic explains what is skipped or wrong and why, position is nil if it is unknown
+type Diagnostic struct {
+	Position  *Position `json:"position,omitempty"`
+	Severity  string    `json:"severity"`
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+	Service   string    `json:"service,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Interface string    `json:"interface,omitempty"`
+}
+
+// text of diagnostic, e.g. accounts/manager.go:16:6: warning: skip field ... [skipped-field]
+func (d *Diagnostic) String() string {
+	var buf strings.Builder
+	if d.Position != nil {
+		buf.WriteString(d.Position.String() + ": ")
+	}
+	fmt.Fprintf(&buf, "%s: %s [%s]", d.Severity, d.describe(), d.Code)
+	return buf.String()
+}
+
+// message with service, path and interface it is about
+func (d *Diagnostic) describe() string {
+	var buf strings.Builder
+	for _, item := range []string{d.Service, d.Path, d.Interface} {
+		if item != "" {
+			buf.WriteString(item + " ")
+		}
+	}
+	buf.WriteString(d.Message)
+	return buf.String()
+}
+
+// diagnostics reported while finding objects and generating, like log they are reported
+// from anywhere, commands take them when they are done
+var reported []*Diagnostic
+
+// report diagnostic
+func Report(d *Diagnostic) {
+	reported = append(reported, d)
+}
+
+// report diagnostic of message formatted
+func Reportf(position *Position, severity string, code string, format string, a ...interface{}) {
+	Report(&Diagnostic{
+		Position: position,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// report diagnostic of object, position of go type is used if position is nil
+func (o *DBusObject) report(position *Position, severity string, code string, format string, a ...interface{}) {
+	if position == nil {
+		position = getSourcePosition(o)
+	}
+	Report(&Diagnostic{
+		Position:  position,
+		Severity:  severity,
+		Code:      code,
+		Message:   fmt.Sprintf(format, a...),
+		Service:   o.serviceName,
+		Path:      o.busPath,
+		Interface: o.interfaceName,
+	})
+}
+
+// check if diagnostic with the same code at the same position is reported already,
+// diagnostics without position are never the same
+func IsReported(d *Diagnostic) bool {
+	if d.Position == nil {
+		return false
+	}
+	for _, r := range reported {
+		if r.Code == d.Code && r.Position != nil && *r.Position == *d.Position {
+			return true
+		}
+	}
+	return false
+}
+
+// take diagnostics reported, they are removed
+func TakeDiagnostics() []*Diagnostic {
+	diagnostics := reported
+	reported = nil
+	return diagnostics
+}
+
+// check if diagnostics fail command, warnings fail it only in strict mode
+func HasFailure(diagnostics []*Diagnostic, strict bool) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError || (strict && d.Severity == SeverityWarning) {
+			return true
+		}
+	}
+	return false
+}
+
+// sort diagnostics by position, diagnostics without position follow others in order reported
+func SortDiagnostics(diagnostics []*Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return positionLess(diagnostics[i].Position, diagnostics[j].Position)
+	})
+}
+
+// converts columns of positions, which are byte offsets in go, to code points,
+// lines of file are read once
+type columnConverter struct {
+	lines map[string][]string
+}
+
+func newColumnConverter() *columnConverter {
+	return &columnConverter{
+		lines: make(map[string][]string),
+	}
+}
+
+// get column counted in code points of byte column at line of file, 0 if line can not be read
+func (cc *columnConverter) convert(file string, line int, column int) int {
+	if column <= 0 || line <= 0 {
+		return 0
+	}
+	lines, ok := cc.lines[file]
+	if !ok {
+		data, err := ioutil.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		cc.lines[file] = lines
+	}
+	if line > len(lines) || column-1 > len(lines[line-1]) {
+		return 0
+	}
+	return utf8.RuneCountInString(lines[line-1][:column-1]) + 1
+}
+
+// formats of diagnostics
+const (
+	DiagnosticsText = "text"
+	DiagnosticsJson = "json"
+	// SARIF 2.1.0, read by code review tools and IDEs
+	DiagnosticsSarif = "sarif"
+	// checkstyle xml, read by CI tools
+	DiagnosticsCheckstyle = "checkstyle"
+)
+
+// check if diagnostics can be written in format
+func CheckDiagnosticsFormat(format string) error {
+	switch format {
+	case DiagnosticsText, DiagnosticsJson, DiagnosticsSarif, DiagnosticsCheckstyle:
+		return nil
+	}
+	return fmt.Errorf("unknown diagnostics format %q, it should be %s, %s, %s or %s",
+		format, DiagnosticsText, DiagnosticsJson, DiagnosticsSarif, DiagnosticsCheckstyle)
+}
+
+// write diagnostics in format, text is one diagnostic each line, json is an array
+func WriteDiagnostics(w io.Writer, diagnostics []*Diagnostic, format string) error {
+	switch format {
+	case DiagnosticsText:
+		for _, d := range diagnostics {
+			_, err := fmt.Fprintln(w, d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case DiagnosticsJson:
+		// empty array instead of null, so tools need not check it
+		if diagnostics == nil {
+			diagnostics = []*Diagnostic{}
+		}
+		data, err := json.MarshalIndent(diagnostics, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case DiagnosticsSarif:
+		return writeSarif(w, diagnostics)
+	case DiagnosticsCheckstyle:
+		return writeCheckstyle(w, diagnostics)
+	default:
+		return CheckDiagnosticsFormat(format)
+	}
+}
@@ -0,0 +1,175 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+
+	C "gopkg.in/check.v1"
+)
+
+type diagnosticSuite struct{}
+
+var _ = C.Suite(&diagnosticSuite{})
+
+// every skipped field of fixture is reported with position and reason code
+func (*diagnosticSuite) TestReportSkippedFields(c *C.C) {
+	TakeDiagnostics()
+	fSet := token.NewFileSet()
+	loadObjects(c, fSet, loadStubs(c, fSet), filepath.Join("testdata", "accounts"))
+	diagnostics := TakeDiagnostics()
+	SortDiagnostics(diagnostics)
+
+	var texts []string
+	for _, d := range diagnostics {
+		c.Check(d.Position, C.NotNil, C.Commentf("%s", d))
+		texts = append(texts, d.String())
+	}
+	c.Assert(texts, C.HasLen, 3)
+	c.Check(texts[0], C.Matches, `testdata/accounts/accounts.go:23:2: info: com.deepin.daemon.Accounts `+
+		`/com/deepin/daemon/Accounts com.deepin.daemon.Accounts skip field Manager.service, `+
+		`reason: field is not exported \[skipped-field\]`)
+	c.Check(texts[1], C.Matches, `testdata/accounts/accounts.go:24:2: info: .* \[skipped-field\]`)
+	c.Check(texts[2], C.Matches, `testdata/accounts/accounts.go:\d+:2: info: .*Cache.* \[skipped-field\]`)
+	c.Check(HasFailure(diagnostics, true), C.Equals, false)
+}
+
+const unresolvedSource = `package stub
+
+import (
+	"example.com/missing"
+	"github.com/godbus/dbus"
+)
+
+type Manager struct {
+	signals *struct {
+		Changed struct {
+			name  string
+			value missing.Value
+		}
+		Removed struct {
+			name string
+		}
+	}
+}
+
+func (*Manager) SetValue(name string, value missing.Value) *dbus.Error { return nil }
+
+func (*Manager) GetName() (string, *dbus.Error) { return "", nil }
+`
+
+// members with args which types can not be resolved are skipped as whole and reported at args
+func (*diagnosticSuite) TestReportUnresolvedArgs(c *C.C) {
+	TakeDiagnostics()
+	fSet := token.NewFileSet()
+	f, err := parser.ParseFile(fSet, "stub.go", unresolvedSource, 0)
+	c.Assert(err, C.IsNil)
+	// missing package can not be imported, args of its types are invalid
+	pkg, _ := (&types.Config{Importer: loadStubs(c, fSet), Error: func(error) {}}).Check("stub", fSet,
+		[]*ast.File{f}, nil)
+	named := pkg.Scope().Lookup("Manager").Type().(*types.Named)
+
+	object := NewDBusObject()
+	object.SetTypesNamed(fSet, named)
+	c.Assert(object.signals, C.HasLen, 1)
+	c.Check(object.signals[0].Name, C.Equals, "Removed")
+	c.Assert(object.methods, C.HasLen, 1)
+	c.Check(object.methods[0].Name, C.Equals, "GetName")
+
+	var texts []string
+	for _, d := range TakeDiagnostics() {
+		texts = append(texts, d.String())
+	}
+	c.Check(texts, C.DeepEquals, []string{
+		"stub.go:12:4: warning: skip signal Changed, reason: arg value: " +
+			"dbus: invalid type invalid type: type can not be resolved [unsupported-type]",
+		"stub.go:20:39: warning: skip method SetValue, reason: arg value: " +
+			"dbus: invalid type invalid type: type can not be resolved [unsupported-type]",
+	})
+}
+
+func (*diagnosticSuite) TestWriteDiagnostics(c *C.C) {
+	diagnostics := []*Diagnostic{
+		{Severity: SeverityWarning, Code: CodeNoObjects, Message: "no bus object is found in ./..."},
+		{
+			Position: &Position{File: "manager.go", Line: 20, Column: 2},
+			Severity: SeverityWarning,
+			Code:     CodeUnsupportedType,
+			Message:  "skip field Manager.Events, reason: channel can not be marshaled",
+		},
+	}
+	SortDiagnostics(diagnostics)
+
+	var buf bytes.Buffer
+	c.Assert(WriteDiagnostics(&buf, diagnostics, DiagnosticsText), C.IsNil)
+	c.Check(buf.String(), C.Equals, "manager.go:20:2: warning: skip field Manager.Events, "+
+		"reason: channel can not be marshaled [unsupported-type]\n"+
+		"warning: no bus object is found in ./... [no-objects]\n")
+
+	buf.Reset()
+	c.Assert(WriteDiagnostics(&buf, diagnostics, DiagnosticsJson), C.IsNil)
+	var decoded []*Diagnostic
+	c.Assert(json.Unmarshal(buf.Bytes(), &decoded), C.IsNil)
+	c.Check(decoded, C.DeepEquals, diagnostics)
+
+	buf.Reset()
+	c.Assert(WriteDiagnostics(&buf, nil, DiagnosticsJson), C.IsNil)
+	c.Check(buf.String(), C.Equals, "[]\n")
+
+	c.Check(WriteDiagnostics(&buf, nil, "xml"), C.ErrorMatches, `unknown diagnostics format "xml".*`)
+}
+
+func (*diagnosticSuite) TestWriteDiagnosticsForTools(c *C.C) {
+	// columns of go are bytes, tools count characters
+	filename := filepath.Join(c.MkDir(), "manager.go")
+	c.Assert(os.WriteFile(filename, []byte("package manager\n\nvar 名字, events = \"\", 0\n"), 0644), C.IsNil)
+	diagnostics := []*Diagnostic{
+		{
+			Position:  &Position{File: filename, Line: 3, Column: 13, EndLine: 3, EndColumn: 19},
+			Severity:  SeverityInfo,
+			Code:      CodeSkippedField,
+			Message:   "skip field Manager.events, reason: field is not exported",
+			Interface: "com.deepin.Test",
+		},
+		{Position: &Position{File: "missing.go", Line: 2, Column: 5}, Severity: SeverityError, Code: CodeInvalidName},
+		{Severity: SeverityWarning, Code: CodeNoObjects, Message: "no bus object is found in ./..."},
+	}
+
+	var buf bytes.Buffer
+	c.Assert(WriteDiagnostics(&buf, diagnostics, DiagnosticsSarif), C.IsNil)
+	var log sarifLog
+	c.Assert(json.Unmarshal(buf.Bytes(), &log), C.IsNil)
+	c.Assert(log.Runs, C.HasLen, 1)
+	rules := log.Runs[0].Tool.Driver.Rules
+	c.Assert(rules, C.HasLen, 3)
+	c.Check(rules[0].Id, C.Equals, CodeInvalidName)
+	results := log.Runs[0].Results
+	c.Assert(results, C.HasLen, 3)
+	c.Check(results[0].RuleIndex, C.Equals, 2)
+	c.Check(results[0].Level, C.Equals, "note")
+	c.Check(results[0].Properties, C.DeepEquals, map[string]string{"interface": "com.deepin.Test"})
+	c.Assert(results[0].Locations, C.HasLen, 1)
+	location := results[0].Locations[0].PhysicalLocation
+	c.Check(location.ArtifactLocation.Uri, C.Equals, "file://"+filepath.ToSlash(filename))
+	c.Check(*location.Region, C.Equals, sarifRegion{StartLine: 3, StartColumn: 9, EndLine: 3, EndColumn: 15})
+	// columns of file which can not be read are left out
+	location = results[1].Locations[0].PhysicalLocation
+	c.Check(location.ArtifactLocation.Uri, C.Equals, "missing.go")
+	c.Check(location.ArtifactLocation.UriBaseId, C.Equals, sarifSrcRoot)
+	c.Check(*location.Region, C.Equals, sarifRegion{StartLine: 2})
+	c.Check(results[2].Level, C.Equals, SeverityWarning)
+	c.Check(results[2].Locations, C.HasLen, 0)
+
+	buf.Reset()
+	c.Assert(WriteDiagnostics(&buf, diagnostics, DiagnosticsCheckstyle), C.IsNil)
+	c.Check(buf.String(), C.Matches, `(?s)<\?xml.*<checkstyle version="4.3">\s*`+
+		`<file name=".*manager.go">\s*<error line="3" column="9" severity="info" `+
+		`message="com.deepin.Test skip field .*" source="deepinAutoWrite.skipped-field">.*`+
+		`<file name="missing.go">\s*<error line="2" severity="error" .*`)
+	c.Check(buf.String(), C.Not(C.Matches), `(?s).*(no-objects|name="").*`)
+}
@@ -0,0 +1,265 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIChange is change of bus api between two models, breaking change makes old clients fail
+type APIChange struct {
+	Breaking  bool
+	Service   string
+	Path      string
+	Interface string
+	Message   string
+}
+
+func (c *APIChange) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	var where []string
+	for _, item := range []string{c.Service, c.Path, c.Interface} {
+		if item != "" {
+			where = append(where, item)
+		}
+	}
+	return fmt.Sprintf("%s: %s: %s", kind, strings.Join(where, " "), c.Message)
+}
+
+// check if any change is breaking
+func HasBreakingChange(changes []*APIChange) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// interface of model with the object and service it is exported at
+type modelEntry struct {
+	service string
+	bus     string
+	path    string
+	itf     *ModelInterface
+}
+
+func (e *modelEntry) key() string {
+	return e.service + ":" + e.path + ":" + e.itf.Name
+}
+
+func getModelEntries(m *Model) []*modelEntry {
+	var entries []*modelEntry
+	for _, service := range m.Services {
+		for _, object := range service.Objects {
+			for _, itf := range object.Interfaces {
+				entries = append(entries, &modelEntry{
+					service: service.Name,
+					bus:     service.Bus,
+					path:    object.Path,
+					itf:     itf,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// compare current model with base model, changes are in order of base model,
+// interfaces and members only in current model follow
+func DiffModels(base *Model, current *Model) []*APIChange {
+	var changes []*APIChange
+	baseEntries := getModelEntries(base)
+	currentEntries := getModelEntries(current)
+	baseKeys := make(map[string]bool)
+	for _, entry := range baseEntries {
+		baseKeys[entry.key()] = true
+	}
+	currentMap := make(map[string]*modelEntry)
+	for _, entry := range currentEntries {
+		currentMap[entry.key()] = entry
+	}
+
+	// match interfaces at the same path first, then interfaces moved to other path,
+	// then interfaces renamed at the same path
+	matched := make(map[*modelEntry]bool)
+	var unmatched []*modelEntry
+	for _, baseEntry := range baseEntries {
+		entry, ok := currentMap[baseEntry.key()]
+		if !ok || matched[entry] {
+			unmatched = append(unmatched, baseEntry)
+			continue
+		}
+		matched[entry] = true
+		changes = append(changes, diffInterface(baseEntry, entry)...)
+	}
+	for _, baseEntry := range unmatched {
+		change := &APIChange{
+			Breaking:  true,
+			Service:   baseEntry.service,
+			Path:      baseEntry.path,
+			Interface: baseEntry.itf.Name,
+		}
+		if entry := findEntry(currentEntries, matched, func(entry *modelEntry) bool {
+			return entry.service == baseEntry.service && entry.itf.Name == baseEntry.itf.Name
+		}); entry != nil {
+			matched[entry] = true
+			change.Message = fmt.Sprintf("path changed to %s", entry.path)
+			changes = append(changes, change)
+			changes = append(changes, diffInterface(baseEntry, entry)...)
+		} else if entry := findEntry(currentEntries, matched, func(entry *modelEntry) bool {
+			// interface which is not in base model at all
+			return entry.service == baseEntry.service && entry.path == baseEntry.path &&
+				entry.path != "" && !baseKeys[entry.key()]
+		}); entry != nil {
+			matched[entry] = true
+			change.Message = fmt.Sprintf("interface renamed to %s", entry.itf.Name)
+			changes = append(changes, change)
+		} else {
+			change.Message = "interface removed"
+			changes = append(changes, change)
+		}
+	}
+	for _, entry := range currentEntries {
+		if matched[entry] {
+			continue
+		}
+		changes = append(changes, &APIChange{
+			Service:   entry.service,
+			Path:      entry.path,
+			Interface: entry.itf.Name,
+			Message:   "interface added",
+		})
+	}
+	return changes
+}
+
+// find first entry which is not matched and satisfies cond
+func findEntry(entries []*modelEntry, matched map[*modelEntry]bool, cond func(entry *modelEntry) bool) *modelEntry {
+	for _, entry := range entries {
+		if !matched[entry] && cond(entry) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// compare members of the same interface
+func diffInterface(base *modelEntry, current *modelEntry) []*APIChange {
+	var changes []*APIChange
+	add := func(breaking bool, format string, a ...interface{}) {
+		changes = append(changes, &APIChange{
+			Breaking:  breaking,
+			Service:   current.service,
+			Path:      current.path,
+			Interface: current.itf.Name,
+			Message:   fmt.Sprintf(format, a...),
+		})
+	}
+	if base.bus != current.bus {
+		add(true, "bus changed from %s to %s", base.bus, current.bus)
+	}
+
+	// methods
+	methods := make(map[string]*DBusMethod)
+	for _, method := range current.itf.Methods {
+		methods[method.Name] = method
+	}
+	for _, baseMethod := range base.itf.Methods {
+		method, ok := methods[baseMethod.Name]
+		if !ok {
+			add(true, "method %s removed", baseMethod.Name)
+			continue
+		}
+		delete(methods, baseMethod.Name)
+		baseSig := getArgsSignature(baseMethod.In) + " -> " + getArgsSignature(baseMethod.Out)
+		sig := getArgsSignature(method.In) + " -> " + getArgsSignature(method.Out)
+		if baseSig != sig {
+			add(true, "method %s changed from %s to %s", method.Name, baseSig, sig)
+		}
+	}
+	for _, method := range current.itf.Methods {
+		if _, ok := methods[method.Name]; ok {
+			add(false, "method %s added", method.Name)
+		}
+	}
+
+	// signals
+	signals := make(map[string]*DBusSignal)
+	for _, signal := range current.itf.Signals {
+		signals[signal.Name] = signal
+	}
+	for _, baseSignal := range base.itf.Signals {
+		signal, ok := signals[baseSignal.Name]
+		if !ok {
+			add(true, "signal %s removed", baseSignal.Name)
+			continue
+		}
+		delete(signals, baseSignal.Name)
+		baseSig := getArgsSignature(baseSignal.Args)
+		sig := getArgsSignature(signal.Args)
+		if baseSig != sig {
+			add(true, "signal %s changed from %s to %s", signal.Name, baseSig, sig)
+		}
+	}
+	for _, signal := range current.itf.Signals {
+		if _, ok := signals[signal.Name]; ok {
+			add(false, "signal %s added", signal.Name)
+		}
+	}
+
+	// properties
+	props := make(map[string]*DBusProperty)
+	for _, prop := range current.itf.Properties {
+		props[prop.Name] = prop
+	}
+	for _, baseProp := range base.itf.Properties {
+		prop, ok := props[baseProp.Name]
+		if !ok {
+			add(true, "property %s removed", baseProp.Name)
+			continue
+		}
+		delete(props, baseProp.Name)
+		if baseProp.Type != prop.Type {
+			add(true, "property %s changed from %s to %s", prop.Name, baseProp.Type, prop.Type)
+		}
+		// clients break only if they can not read or write it any more
+		if baseProp.Access != prop.Access {
+			breaking := (baseProp.CanRead() && !prop.CanRead()) || (baseProp.CanWrite() && !prop.CanWrite())
+			add(breaking, "access of property %s changed from %s to %s", prop.Name, baseProp.Access, prop.Access)
+		}
+		// clients watching property break if they get less of changes
+		if baseProp.Emit != prop.Emit {
+			breaking := emitRanks[prop.Emit] < emitRanks[baseProp.Emit]
+			add(breaking, "emit of property %s changed from %s to %s", prop.Name, baseProp.Emit, prop.Emit)
+		}
+	}
+	for _, prop := range current.itf.Properties {
+		if _, ok := props[prop.Name]; ok {
+			add(false, "property %s added", prop.Name)
+		}
+	}
+	return changes
+}
+
+// what clients get when property changes, more is better, e.g. value is sent if emit is true
+// but only name if invalidates, clients caching const property break if it changes
+var emitRanks = map[string]int{
+	EmitFalse:       0,
+	EmitInvalidates: 1,
+	EmitTrue:        2,
+	EmitConst:       3,
+}
+
+// get signature of args, e.g. (ssi)
+func getArgsSignature(args []*DBusArg) string {
+	var buf strings.Builder
+	buf.WriteString("(")
+	for _, arg := range args {
+		buf.WriteString(arg.Type)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
@@ -0,0 +1,114 @@
+package writeGoFile
+
+import (
+	C "gopkg.in/check.v1"
+)
+
+type diffSuite struct{}
+
+var _ = C.Suite(&diffSuite{})
+
+func newDiffModel(path string, itfName string, itf *ModelInterface) *Model {
+	itf.Name = itfName
+	return &Model{
+		Version: ModelVersion,
+		Services: []*ModelService{{
+			Name:    "com.deepin.Test",
+			Bus:     "session",
+			Objects: []*ModelObject{{Path: path, Interfaces: []*ModelInterface{itf}}},
+		}},
+	}
+}
+
+// check if change of property is breaking, property must be changed
+func diffProperty(c *C.C, baseProp *DBusProperty, prop *DBusProperty) bool {
+	baseProp.Name, baseProp.Type = "Name", "s"
+	prop.Name, prop.Type = "Name", "s"
+	changes := DiffModels(newDiffModel("/com/deepin/Test", "com.deepin.Test",
+		&ModelInterface{Properties: []*DBusProperty{baseProp}}),
+		newDiffModel("/com/deepin/Test", "com.deepin.Test", &ModelInterface{Properties: []*DBusProperty{prop}}))
+	c.Assert(changes, C.HasLen, 1)
+	return changes[0].Breaking
+}
+
+func (*diffSuite) TestDiffModels(c *C.C) {
+	base := newDiffModel("/com/deepin/Test", "com.deepin.Test", &ModelInterface{
+		Methods: []*DBusMethod{
+			{Name: "Get", In: []*DBusArg{{Name: "key", Type: "s"}}, Out: []*DBusArg{{Type: "v"}}},
+			{Name: "Reset"},
+		},
+		Signals: []*DBusSignal{{Name: "Changed", Args: []*DBusArg{{Type: "s"}}}},
+		Properties: []*DBusProperty{
+			{Name: "Name", Type: "s", Access: AccessReadWrite, Emit: EmitTrue},
+			{Name: "Level", Type: "i", Access: AccessRead, Emit: EmitTrue},
+		},
+	})
+
+	// arg names are not part of api
+	current := newDiffModel("/com/deepin/Test", "com.deepin.Test", &ModelInterface{
+		Methods: []*DBusMethod{
+			{Name: "Get", In: []*DBusArg{{Name: "name", Type: "s"}}, Out: []*DBusArg{{Type: "v"}}},
+			{Name: "Reset"},
+			{Name: "Ping"},
+		},
+		Signals: []*DBusSignal{{Name: "Changed", Args: []*DBusArg{{Type: "s"}}}},
+		Properties: []*DBusProperty{
+			{Name: "Name", Type: "s", Access: AccessReadWrite, Emit: EmitTrue},
+			{Name: "Level", Type: "i", Access: AccessRead, Emit: EmitTrue},
+			{Name: "Icon", Type: "s", Access: AccessRead, Emit: EmitTrue},
+		},
+	})
+	changes := DiffModels(base, current)
+	c.Check(HasBreakingChange(changes), C.Equals, false)
+	c.Assert(changes, C.HasLen, 2)
+	c.Check(changes[0].Message, C.Equals, "method Ping added")
+	c.Check(changes[1].Message, C.Equals, "property Icon added")
+
+	current = newDiffModel("/com/deepin/Test", "com.deepin.Test", &ModelInterface{
+		Methods: []*DBusMethod{
+			{Name: "Get", In: []*DBusArg{{Type: "s"}, {Type: "b"}}, Out: []*DBusArg{{Type: "v"}}},
+		},
+		Signals: []*DBusSignal{{Name: "Changed", Args: []*DBusArg{{Type: "i"}}}},
+		Properties: []*DBusProperty{
+			{Name: "Name", Type: "s", Access: AccessRead, Emit: EmitFalse},
+		},
+	})
+	var messages []string
+	for _, change := range DiffModels(base, current) {
+		c.Check(change.Breaking, C.Equals, true, C.Commentf("%s", change))
+		messages = append(messages, change.Message)
+	}
+	c.Check(messages, C.DeepEquals, []string{
+		"method Get changed from (s) -> (v) to (sb) -> (v)",
+		"method Reset removed",
+		"signal Changed changed from (s) to (i)",
+		"access of property Name changed from readwrite to read",
+		"emit of property Name changed from true to false",
+		"property Level removed",
+	})
+
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitTrue},
+		&DBusProperty{Access: AccessReadWrite, Emit: EmitTrue}), C.Equals, false)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessReadWrite, Emit: EmitTrue},
+		&DBusProperty{Access: AccessWrite, Emit: EmitTrue}), C.Equals, true)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessWrite, Emit: EmitTrue},
+		&DBusProperty{Access: AccessRead, Emit: EmitTrue}), C.Equals, true)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitTrue},
+		&DBusProperty{Access: AccessRead, Emit: EmitInvalidates}), C.Equals, true)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitInvalidates},
+		&DBusProperty{Access: AccessRead, Emit: EmitFalse}), C.Equals, true)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitConst},
+		&DBusProperty{Access: AccessRead, Emit: EmitTrue}), C.Equals, true)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitFalse},
+		&DBusProperty{Access: AccessRead, Emit: EmitInvalidates}), C.Equals, false)
+	c.Check(diffProperty(c, &DBusProperty{Access: AccessRead, Emit: EmitTrue},
+		&DBusProperty{Access: AccessRead, Emit: EmitConst}), C.Equals, false)
+
+	changes = DiffModels(base, newDiffModel("/com/deepin/Test", "com.deepin.Test2", &ModelInterface{}))
+	c.Assert(changes, C.HasLen, 1)
+	c.Check(changes[0].String(), C.Equals,
+		"breaking: com.deepin.Test /com/deepin/Test com.deepin.Test: interface renamed to com.deepin.Test2")
+
+	changes = DiffModels(base, newDiffModel("/com/deepin/Test2", "com.deepin.Test", &ModelInterface{}))
+	c.Check(changes[0].Message, C.Equals, "path changed to /com/deepin/Test2")
+}
@@ -0,0 +1,96 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// write markdown docs of api, services, objects and interfaces are kept in order of objects
+func WriteDocs(w io.Writer, objects []*DBusObject) error {
+	var buf bytes.Buffer
+	model := NewModel(objects)
+	fmt.Fprintf(&buf, "<!-- %s -->\n<!-- input hash: %s -->\n\n", GeneratedComment, model.InputHash)
+	fmt.Fprintf(&buf, "# D-Bus API\n")
+	for _, service := range model.Services {
+		name := service.Name
+		if name == "" {
+			name = "unknown service"
+		}
+		fmt.Fprintf(&buf, "\n## %s\n", name)
+		if service.Bus != "" {
+			fmt.Fprintf(&buf, "\nbus: %s\n", service.Bus)
+		}
+		for _, object := range service.Objects {
+			path := object.Path
+			if path == "" {
+				path = "unknown path"
+			}
+			fmt.Fprintf(&buf, "\n### %s\n", path)
+			for _, itf := range object.Interfaces {
+				writeDocsInterface(&buf, itf)
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeDocsInterface(buf *bytes.Buffer, itf *ModelInterface) {
+	fmt.Fprintf(buf, "\n#### %s\n", itf.Name)
+	if source := itf.Source; source != nil {
+		fmt.Fprintf(buf, "\nimplemented by `%s.%s`", source.PackagePath, source.Type)
+		if source.Position != nil {
+			fmt.Fprintf(buf, " in %s", source.Position)
+		}
+		buf.WriteString("\n")
+	}
+	if len(itf.Methods) > 0 {
+		buf.WriteString("\nmethods:\n\n")
+		for _, method := range itf.Methods {
+			fmt.Fprintf(buf, "- `%s(%s)", method.Name, getDocsArgs(method.In))
+			if len(method.Out) > 0 {
+				fmt.Fprintf(buf, " -> (%s)", getDocsArgs(method.Out))
+			}
+			buf.WriteString("`\n")
+		}
+	}
+	if len(itf.Signals) > 0 {
+		buf.WriteString("\nsignals:\n\n")
+		for _, signal := range itf.Signals {
+			fmt.Fprintf(buf, "- `%s(%s)`\n", signal.Name, getDocsArgs(signal.Args))
+		}
+	}
+	if len(itf.Properties) > 0 {
+		buf.WriteString("\nproperties:\n\n")
+		buf.WriteString("| name | type | access | emits changed |\n")
+		buf.WriteString("| ---- | ---- | ------ | ------------- |\n")
+		for _, prop := range itf.Properties {
+			fmt.Fprintf(buf, "| %s | `%s` | %s | %s |\n", prop.Name, prop.Type, prop.Access, prop.Emit)
+		}
+	}
+}
+
+// args are written as name type, type is D-Bus signature
+func getDocsArgs(args []*DBusArg) string {
+	var items []string
+	for _, arg := range args {
+		if arg.Name == "" {
+			items = append(items, arg.Type)
+			continue
+		}
+		items = append(items, arg.Name+" "+arg.Type)
+	}
+	return strings.Join(items, ", ")
+}
+
+// save markdown docs of api to file
+func SaveDocs(out Output, filename string, objects []*DBusObject) error {
+	var buf bytes.Buffer
+	err := WriteDocs(&buf, objects)
+	if err != nil {
+		return err
+	}
+	return out.WriteFile(filename, buf.Bytes())
+}
@@ -0,0 +1,77 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	C "gopkg.in/check.v1"
+)
+
+type docsSuite struct{}
+
+var _ = C.Suite(&docsSuite{})
+
+func (*docsSuite) TestWriteDocs(c *C.C) {
+	user := NewDBusObject()
+	user.serviceName = "com.deepin.daemon.Accounts"
+	user.busType = SystemBus
+	user.busPath = "/com/deepin/daemon/Accounts/User{uid}"
+	user.interfaceName = "com.deepin.daemon.Accounts.User"
+	user.source = &ObjectSource{
+		Package:     "accounts",
+		PackagePath: "pkg.deepin.io/dde/daemon/accounts",
+		Type:        "User",
+		Position:    &Position{File: "accounts/user.go", Line: 12, Column: 6},
+	}
+	user.methods = []*DBusMethod{
+		{Name: "SetIcon", In: []*DBusArg{{Name: "icon", Type: "s"}}},
+		{Name: "GetGroups", Out: []*DBusArg{{Type: "as"}, {Name: "count", Type: "i"}}},
+	}
+	user.signals = []*DBusSignal{{Name: "Changed", Args: []*DBusArg{{Name: "name", Type: "s"}}}}
+	user.properties = []*DBusProperty{{Name: "UserName", Type: "s", Access: AccessRead, Emit: EmitTrue}}
+	// service and path are unknown
+	unknown := NewDBusObject()
+	unknown.interfaceName = "com.deepin.Unknown"
+
+	var buf bytes.Buffer
+	c.Assert(WriteDocs(&buf, []*DBusObject{user, unknown}), C.IsNil)
+	// ' is used as backquote in expected docs
+	expected := strings.Replace(`<!-- %s -->
+<!-- input hash: %s -->
+
+# D-Bus API
+
+## com.deepin.daemon.Accounts
+
+bus: system
+
+### /com/deepin/daemon/Accounts/User{uid}
+
+#### com.deepin.daemon.Accounts.User
+
+implemented by 'pkg.deepin.io/dde/daemon/accounts.User' in accounts/user.go:12:6
+
+methods:
+
+- 'SetIcon(icon s)'
+- 'GetGroups() -> (as, count i)'
+
+signals:
+
+- 'Changed(name s)'
+
+properties:
+
+| name | type | access | emits changed |
+| ---- | ---- | ------ | ------------- |
+| UserName | 's' | read | true |
+
+## unknown service
+
+### unknown path
+
+#### com.deepin.Unknown
+`, "'", "`", -1)
+	c.Check(buf.String(), C.Equals, fmt.Sprintf(expected, GeneratedComment, InputHash([]*DBusObject{user, unknown})))
+}
@@ -0,0 +1,135 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// config file name of go-dbus-factory service dir
+const factoryConfigFileName = "config.json"
+
+// FactoryConfig is config.json of go-dbus-factory, lists objects of a service
+type FactoryConfig struct {
+	Generated string `json:",omitempty"`
+	InputHash string `json:",omitempty"`
+	Service   string
+	Objects   []*FactoryObject
+}
+
+// FactoryObject is proxy object in go-dbus-factory config, path is empty if it is built at runtime
+type FactoryObject struct {
+	Type       string
+	Path       string `json:",omitempty"`
+	Interfaces []*FactoryInterface
+}
+
+// FactoryInterface is interface of proxy object, fixes override types of members
+type FactoryInterface struct {
+	Name  string
+	Type  string
+	Fixes map[string]*FactoryFix `json:",omitempty"`
+}
+
+// FactoryFix set go type of member, key of fix is p/<Property>
+type FactoryFix struct {
+	Type      string `json:",omitempty"`
+	ValueType string `json:",omitempty"`
+}
+
+// create config of proxy objects of service
+func NewFactoryConfig(serviceName string, proxies []*ProxyObject) *FactoryConfig {
+	var objects []*DBusObject
+	for _, proxy := range proxies {
+		objects = append(objects, proxy.interfaces...)
+	}
+	config := &FactoryConfig{
+		Generated: GeneratedComment,
+		InputHash: InputHash(objects),
+		Service:   serviceName,
+	}
+	for _, proxy := range proxies {
+		object := &FactoryObject{
+			Type: proxy.TypeName,
+		}
+		if !proxy.IsPathTemplate() {
+			object.Path = proxy.busPath
+		}
+		for _, busObject := range proxy.interfaces {
+			object.Interfaces = append(object.Interfaces, &FactoryInterface{
+				Name:  busObject.interfaceName,
+				Type:  busObject.ObjectName,
+				Fixes: getPropertyFixes(busObject),
+			})
+		}
+		config.Objects = append(config.Objects, object)
+	}
+	return config
+}
+
+// complex properties have no proxy.PropXxx type, they are fixed to generated types
+func getPropertyFixes(object *DBusObject) map[string]*FactoryFix {
+	fixes := make(map[string]*FactoryFix)
+	for _, prop := range object.properties {
+		if getPropType(prop.Type) != "" {
+			continue
+		}
+		goType, err := GoTypeOf(prop.Type)
+		if err != nil {
+			continue
+		}
+		fixes["p/"+prop.Name] = &FactoryFix{
+			Type:      getComplexPropType(object, prop),
+			ValueType: goType,
+		}
+	}
+	if len(fixes) == 0 {
+		return nil
+	}
+	return fixes
+}
+
+// save introspect xml of every proxy object and config.json to the same dir in outDir as
+// go proxies, see SaveProxyFiles, saved file names are returned
+func SaveFactoryFiles(out Output, outDir string, objects []*DBusObject) ([]string, error) {
+	dirs, dirObjects := groupByOutputDir(objects)
+	var filenames []string
+	for _, dir := range dirs {
+		// config.json lists objects of one service
+		service := dirObjects[dir][0].serviceName
+		for _, object := range dirObjects[dir] {
+			if object.serviceName != service {
+				return filenames, fmt.Errorf("objects of services %s and %s can not be in the same dir %s",
+					service, object.serviceName, dir)
+			}
+		}
+		proxies := GroupDBusObjects(dirObjects[dir])
+		dir = filepath.Join(outDir, dir)
+		// xml is named after object type, same as go-dbus-factory
+		for _, proxy := range proxies {
+			filename := filepath.Join(dir, proxy.TypeName+".xml")
+			var buf bytes.Buffer
+			err := WriteProxyXml(&buf, proxy)
+			if err == nil {
+				err = out.WriteFile(filename, buf.Bytes())
+			}
+			if err != nil {
+				return filenames, err
+			}
+			filenames = append(filenames, filename)
+		}
+
+		data, err := json.MarshalIndent(NewFactoryConfig(service, proxies), "", "  ")
+		if err != nil {
+			return filenames, err
+		}
+		filename := filepath.Join(dir, factoryConfigFileName)
+		err = out.WriteFile(filename, append(data, '\n'))
+		if err != nil {
+			return filenames, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
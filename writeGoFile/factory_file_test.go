@@ -0,0 +1,51 @@
+package writeGoFile
+
+import (
+	C "gopkg.in/check.v1"
+)
+
+type factorySuite struct{}
+
+var _ = C.Suite(&factorySuite{})
+
+func (*factorySuite) TestNewFactoryConfig(c *C.C) {
+	manager := newTestObject("com.deepin.daemon.Accounts", "/com/deepin/daemon/Accounts",
+		"com.deepin.daemon.Accounts")
+	manager.properties = []*DBusProperty{{Name: "UserList", Type: "as"}}
+	user := newTestObject("com.deepin.daemon.Accounts", "/com/deepin/daemon/Accounts/User{uid}",
+		"com.deepin.daemon.Accounts.User")
+	user.properties = []*DBusProperty{
+		{Name: "UserName", Type: "s"},
+		{Name: "Groups", Type: "a{sv}"},
+		{Name: "Size", Type: "(ii)"},
+		{Name: "Broken", Type: "a{"},
+	}
+	proxies := GroupDBusObjects([]*DBusObject{manager, user})
+
+	config := NewFactoryConfig("com.deepin.daemon.Accounts", proxies)
+	c.Check(config.Service, C.Equals, "com.deepin.daemon.Accounts")
+	c.Check(config.InputHash, C.Equals, InputHash([]*DBusObject{manager, user}))
+	c.Assert(config.Objects, C.HasLen, 2)
+
+	c.Check(config.Objects[0], C.DeepEquals, &FactoryObject{
+		Type: "Accounts",
+		Path: "/com/deepin/daemon/Accounts",
+		Interfaces: []*FactoryInterface{
+			{Name: "com.deepin.daemon.Accounts", Type: "accounts"},
+		},
+	})
+	// path template is passed to constructor, only properties without proxy.PropXxx type are fixed
+	c.Check(config.Objects[1], C.DeepEquals, &FactoryObject{
+		Type: "User",
+		Interfaces: []*FactoryInterface{
+			{
+				Name: "com.deepin.daemon.Accounts.User",
+				Type: "user",
+				Fixes: map[string]*FactoryFix{
+					"p/Groups": {Type: "PropUserGroups", ValueType: "map[string]dbus.Variant"},
+					"p/Size":   {Type: "PropUserSize", ValueType: "struct {Field0 int32; Field1 int32}"},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,165 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// max length of D-Bus names
+const maxNameLen = 255
+
+var (
+	// element of interface name, it can not start with digit
+	interfaceElemReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// element of well-known bus name, it can contain '-' but can not start with digit
+	serviceElemReg = regexp.MustCompile(`^[A-Za-z_-][A-Za-z0-9_-]*$`)
+	memberReg      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	pathElemReg    = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	// placeholder of path template, like {uid}
+	pathPlaceholderReg = regexp.MustCompile(`\{[^{}/]*\}`)
+)
+
+// find problems of objects: unknown export info, invalid names and signatures,
+// and interfaces exported twice at the same path
+func LintDBusObjects(objects []*DBusObject) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	exported := make(map[string]bool)
+	for _, object := range objects {
+		report := func(position *Position, severity string, code string, format string, a ...interface{}) {
+			if position == nil {
+				position = getSourcePosition(object)
+			}
+			diagnostics = append(diagnostics, &Diagnostic{
+				Position:  position,
+				Severity:  severity,
+				Code:      code,
+				Message:   fmt.Sprintf(format, a...),
+				Service:   object.serviceName,
+				Path:      object.busPath,
+				Interface: object.interfaceName,
+			})
+		}
+
+		// export info comes from export call, problems of it are reported there
+		exportPosition := getExportPosition(object)
+		if object.serviceName == "" {
+			report(exportPosition, SeverityWarning, CodeUnknownService, "service name is unknown")
+		} else if err := checkServiceName(object.serviceName); err != nil {
+			report(nil, SeverityError, CodeInvalidName, "invalid service name: %v", err)
+		}
+		if object.busType == "" {
+			report(exportPosition, SeverityWarning, CodeUnknownService, "bus type is unknown")
+		} else if object.busType != "system" && object.busType != "session" {
+			report(nil, SeverityError, CodeInvalidName, "invalid bus type %q, it should be system or session",
+				object.busType)
+		}
+		if object.busPath == "" {
+			report(exportPosition, SeverityWarning, CodeUnresolvedPath, "path is unknown")
+		} else if err := checkObjectPath(object.busPath); err != nil {
+			report(nil, SeverityError, CodeInvalidName, "invalid path: %v", err)
+		}
+		if err := checkInterfaceName(object.interfaceName); err != nil {
+			report(nil, SeverityError, CodeInvalidName, "invalid interface name: %v", err)
+		}
+
+		// path is unknown, can not tell if interface is exported twice
+		if object.busPath != "" {
+			key := object.serviceName + ":" + object.busPath + ":" + object.interfaceName
+			if exported[key] {
+				report(nil, SeverityError, CodeDuplicateInterface,
+					"interface is exported more than once at the same path")
+			}
+			exported[key] = true
+		}
+
+		for _, method := range object.methods {
+			if err := checkMemberName(method.Name); err != nil {
+				report(method.Position, SeverityError, CodeInvalidName, "invalid method name: %v", err)
+			}
+			if err := checkArgTypes(method.In, method.Out); err != nil {
+				report(method.Position, SeverityError, CodeUnsupportedType, "method %s: %v", method.Name, err)
+			}
+		}
+		for _, signal := range object.signals {
+			if err := checkMemberName(signal.Name); err != nil {
+				report(signal.Position, SeverityError, CodeInvalidName, "invalid signal name: %v", err)
+			}
+			if err := checkArgTypes(signal.Args); err != nil {
+				report(signal.Position, SeverityError, CodeUnsupportedType, "signal %s: %v", signal.Name, err)
+			}
+		}
+		for _, prop := range object.properties {
+			if err := checkMemberName(prop.Name); err != nil {
+				report(prop.Position, SeverityError, CodeInvalidName, "invalid property name: %v", err)
+			}
+			if _, err := GoTypeOf(prop.Type); err != nil {
+				report(prop.Position, SeverityError, CodeUnsupportedType, "property %s: %v", prop.Name, err)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// get position of export call of object, position of go type is used if it is unknown
+func getExportPosition(object *DBusObject) *Position {
+	if object.source != nil && object.source.Export != nil {
+		return object.source.Export
+	}
+	return getSourcePosition(object)
+}
+
+// check name made of elements separated by '.', at least two elements are needed
+func checkDottedName(name string, elemReg *regexp.Regexp) error {
+	if name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if len(name) > maxNameLen {
+		return fmt.Errorf("%q is longer than %d", name, maxNameLen)
+	}
+	elems := strings.Split(name, ".")
+	if len(elems) < 2 {
+		return fmt.Errorf("%q should have at least two elements separated by '.'", name)
+	}
+	for _, elem := range elems {
+		if !elemReg.MatchString(elem) {
+			return fmt.Errorf("%q has invalid element %q", name, elem)
+		}
+	}
+	return nil
+}
+
+func checkInterfaceName(name string) error {
+	return checkDottedName(name, interfaceElemReg)
+}
+
+func checkServiceName(name string) error {
+	return checkDottedName(name, serviceElemReg)
+}
+
+func checkMemberName(name string) error {
+	if len(name) > maxNameLen {
+		return fmt.Errorf("%q is longer than %d", name, maxNameLen)
+	}
+	if !memberReg.MatchString(name) {
+		return fmt.Errorf("%q should only contain letters, digits and '_', and not start with digit", name)
+	}
+	return nil
+}
+
+// check object path, placeholders of path template are valid elements
+func checkObjectPath(path string) error {
+	if path == "/" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") {
+		return fmt.Errorf("%q should start with '/' and not end with '/'", path)
+	}
+	resolved := pathPlaceholderReg.ReplaceAllString(path, "_")
+	for _, elem := range strings.Split(resolved[1:], "/") {
+		if !pathElemReg.MatchString(elem) {
+			return fmt.Errorf("%q has invalid element %q", path, elem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package writeGoFile
+
+import (
+	C "gopkg.in/check.v1"
+)
+
+type lintSuite struct{}
+
+var _ = C.Suite(&lintSuite{})
+
+func getLintMessages(diagnostics []*Diagnostic) []string {
+	var messages []string
+	for _, d := range diagnostics {
+		messages = append(messages, d.String())
+	}
+	return messages
+}
+
+func (*lintSuite) TestLintValid(c *C.C) {
+	object := newTestObject("com.deepin.daemon.Accounts", "/com/deepin/daemon/Accounts/User{uid}",
+		"com.deepin.daemon.Accounts.User")
+	object.methods = []*DBusMethod{{Name: "SetName", In: []*DBusArg{{Name: "name", Type: "s"}}}}
+	object.properties = []*DBusProperty{{Name: "Locked", Type: "b", Access: "read"}}
+	c.Check(LintDBusObjects([]*DBusObject{object}), C.HasLen, 0)
+}
+
+func (*lintSuite) TestLintProblems(c *C.C) {
+	object := newTestObject("", "com/deepin/Test", "Test")
+	object.methods = []*DBusMethod{
+		{Name: "1Reset", Position: &Position{File: "test.go", Line: 10}},
+		{Name: "Get", Out: []*DBusArg{{Name: "value", Type: "a"}}, Position: &Position{File: "test.go", Line: 20}},
+	}
+	object.properties = []*DBusProperty{{Name: "Name", Type: "s", Access: "read"}}
+	first := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test")
+	second := newTestObject("com.deepin.Test", "/com/deepin/Test", "com.deepin.Test")
+	second.SetBusType("user")
+
+	diagnostics := LintDBusObjects([]*DBusObject{object, first, second})
+	messages := getLintMessages(diagnostics)
+	c.Assert(messages, C.HasLen, 7)
+	c.Check(messages[0], C.Equals,
+		"test.go:1: warning: com/deepin/Test Test service name is unknown [unknown-service]")
+	c.Check(messages[1], C.Matches, `test.go:1: error: com/deepin/Test Test invalid path: .* \[invalid-name\]`)
+	c.Check(messages[2], C.Matches, `test.go:1: error: com/deepin/Test Test invalid interface name: .*two elements.*`)
+	c.Check(messages[3], C.Matches, `test.go:10: error: .* invalid method name: "1Reset" .*`)
+	c.Check(messages[4], C.Matches, `test.go:20: error: .* method Get: arg value: .* \[unsupported-type\]`)
+	c.Check(messages[5], C.Matches, `test.go:1: error: com.deepin.Test .* invalid bus type "user".*`)
+	c.Check(messages[6], C.Matches, `test.go:1: error: com.deepin.Test .* interface is exported more than once .*`)
+	c.Check(HasFailure(diagnostics[:1], false), C.Equals, false)
+	c.Check(HasFailure(diagnostics[:1], true), C.Equals, true)
+	c.Check(HasFailure(diagnostics, false), C.Equals, true)
+}
+
+// unknown export info is reported at export call, same as when loading, so it is reported once
+func (*lintSuite) TestLintReported(c *C.C) {
+	TakeDiagnostics()
+	exportPosition := &Position{File: "test.go", Line: 30, Column: 2, EndLine: 30, EndColumn: 40}
+	object := newTestObject("", "", "com.deepin.Test")
+	object.SetBusType("")
+	object.source.Export = exportPosition
+	Reportf(&Position{File: "test.go", Line: 30, Column: 2, EndLine: 30, EndColumn: 40}, SeverityWarning,
+		CodeUnknownService, "can not determine service name or bus type of Test")
+
+	diagnostics := LintDBusObjects([]*DBusObject{object})
+	c.Assert(diagnostics, C.HasLen, 3)
+	for _, d := range diagnostics {
+		c.Check(d.Position, C.DeepEquals, exportPosition)
+	}
+	c.Check(diagnostics[0].Code, C.Equals, CodeUnknownService)
+	c.Check(IsReported(diagnostics[0]), C.Equals, true)
+	c.Check(diagnostics[1].Code, C.Equals, CodeUnknownService)
+	c.Check(IsReported(diagnostics[1]), C.Equals, true)
+	c.Check(diagnostics[2].Code, C.Equals, CodeUnresolvedPath)
+	c.Check(IsReported(diagnostics[2]), C.Equals, false)
+
+	// diagnostics without position can not be told apart
+	Reportf(nil, SeverityWarning, CodeUnknownService, "service name is unknown")
+	c.Check(IsReported(&Diagnostic{Code: CodeUnknownService}), C.Equals, false)
+	TakeDiagnostics()
+}
@@ -0,0 +1,283 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// version of model schema, it is increased when model is changed incompatibly,
+// see docs/model.md
+const ModelVersion = 1
+
+// Model is every bus object found in source, generators can run from it instead of source
+type Model struct {
+	Version   int             `json:"version" yaml:"version"`
+	Generated string          `json:"generated,omitempty" yaml:"generated,omitempty"`
+	InputHash string          `json:"inputHash,omitempty" yaml:"inputHash,omitempty"`
+	Services  []*ModelService `json:"services" yaml:"services"`
+}
+
+// ModelService is service exported on bus, name is empty if it can not be determined
+type ModelService struct {
+	Name    string         `json:"name" yaml:"name"`
+	Bus     string         `json:"bus,omitempty" yaml:"bus,omitempty"`
+	Objects []*ModelObject `json:"objects" yaml:"objects"`
+}
+
+// ModelObject is object at path, path may be template like /com/deepin/daemon/Accounts/User{uid}
+type ModelObject struct {
+	Path       string            `json:"path" yaml:"path"`
+	Interfaces []*ModelInterface `json:"interfaces" yaml:"interfaces"`
+}
+
+// ModelInterface is interface exported at path of object, types of members are D-Bus signatures
+type ModelInterface struct {
+	Name        string            `json:"name" yaml:"name"`
+	Source      *ObjectSource     `json:"source,omitempty" yaml:"source,omitempty"`
+	Annotations []*DBusAnnotation `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Methods     []*DBusMethod     `json:"methods,omitempty" yaml:"methods,omitempty"`
+	Signals     []*DBusSignal     `json:"signals,omitempty" yaml:"signals,omitempty"`
+	Properties  []*DBusProperty   `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// ObjectSource is go type which implements interface
+type ObjectSource struct {
+	Package     string `json:"package" yaml:"package"`
+	PackagePath string `json:"packagePath" yaml:"packagePath"`
+	Dir         string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Type        string `json:"type" yaml:"type"`
+	// position of type declaration
+	Position *Position `json:"position,omitempty" yaml:"position,omitempty"`
+	// position of export call
+	Export *Position `json:"export,omitempty" yaml:"export,omitempty"`
+}
+
+// Position is position in go source, end is set if range of source is known
+type Position struct {
+	File      string `json:"file" yaml:"file"`
+	Line      int    `json:"line" yaml:"line"`
+	Column    int    `json:"column,omitempty" yaml:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty" yaml:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty" yaml:"endColumn,omitempty"`
+}
+
+// get position of pos, nil is returned if it is unknown
+func NewPosition(fSet *token.FileSet, pos token.Pos) *Position {
+	if fSet == nil || !pos.IsValid() {
+		return nil
+	}
+	return PositionOf(fSet.Position(pos))
+}
+
+// get range from pos to end, end is not set if it is unknown
+func NewRange(fSet *token.FileSet, pos token.Pos, end token.Pos) *Position {
+	if fSet == nil || !pos.IsValid() {
+		return nil
+	}
+	return RangeOf(fSet.Position(pos), fSet.Position(end))
+}
+
+// get range of name of object, e.g. name of type, field or method
+func NewNameRange(fSet *token.FileSet, obj types.Object) *Position {
+	return NewRange(fSet, obj.Pos(), obj.Pos()+token.Pos(len(obj.Name())))
+}
+
+func RangeOf(position token.Position, end token.Position) *Position {
+	result := PositionOf(position)
+	if result != nil && end.IsValid() {
+		result.EndLine = end.Line
+		result.EndColumn = end.Column
+	}
+	return result
+}
+
+func PositionOf(position token.Position) *Position {
+	if !position.IsValid() {
+		return nil
+	}
+	return &Position{
+		File:   relativeFile(position.Filename),
+		Line:   position.Line,
+		Column: position.Column,
+	}
+}
+
+// file in current dir is relative to it, so positions do not depend on where tree is
+func relativeFile(filename string) string {
+	if !filepath.IsAbs(filename) {
+		return filename
+	}
+	current, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(current, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (p *Position) String() string {
+	if p.Column == 0 {
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// create model of objects, services and objects are kept in order they appear,
+// interfaces exported at the same path are grouped into one object
+func NewModel(objects []*DBusObject) *Model {
+	model := &Model{
+		Version:   ModelVersion,
+		Generated: GeneratedComment,
+		InputHash: InputHash(objects),
+	}
+	serviceMap := make(map[string]*ModelService)
+	objectMap := make(map[string]*ModelObject)
+	for _, object := range objects {
+		serviceKey := object.serviceName + ":" + object.busType
+		service, ok := serviceMap[serviceKey]
+		if !ok {
+			service = &ModelService{
+				Name: object.serviceName,
+				Bus:  object.busType,
+			}
+			serviceMap[serviceKey] = service
+			model.Services = append(model.Services, service)
+		}
+		// path can not be resolved, can not group with others
+		objectKey := serviceKey + ":" + object.busPath
+		modelObject, ok := objectMap[objectKey]
+		if !ok || object.busPath == "" {
+			modelObject = &ModelObject{
+				Path: object.busPath,
+			}
+			objectMap[objectKey] = modelObject
+			service.Objects = append(service.Objects, modelObject)
+		}
+		modelObject.Interfaces = append(modelObject.Interfaces, &ModelInterface{
+			Name:        object.interfaceName,
+			Source:      object.source,
+			Annotations: object.annotations,
+			Methods:     object.methods,
+			Signals:     object.signals,
+			Properties:  object.properties,
+		})
+	}
+	return model
+}
+
+// convert model to bus objects, one object for each interface
+func (m *Model) DBusObjects() []*DBusObject {
+	var objects []*DBusObject
+	for _, service := range m.Services {
+		for _, modelObject := range service.Objects {
+			for _, itf := range modelObject.Interfaces {
+				object := NewDBusObject()
+				object.SetServiceName(service.Name)
+				object.SetBusType(service.Bus)
+				object.SetDBusPath(modelObject.Path)
+				object.SetInterfaceName(itf.Name)
+				object.source = itf.Source
+				object.annotations = itf.Annotations
+				object.methods = itf.Methods
+				object.signals = itf.Signals
+				object.properties = itf.Properties
+				objects = append(objects, object)
+			}
+		}
+	}
+	return objects
+}
+
+// check if model can be used by generators
+func (m *Model) Check() error {
+	if m.Version == 0 {
+		return errors.New("model version is missing")
+	}
+	if m.Version > ModelVersion {
+		return fmt.Errorf("model version %d is newer than supported version %d", m.Version, ModelVersion)
+	}
+	for _, service := range m.Services {
+		for _, object := range service.Objects {
+			for _, itf := range object.Interfaces {
+				if itf.Name == "" {
+					return fmt.Errorf("interface name of object at %q is empty", object.Path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// check if model file is yaml by extension, json is used for others
+func isYamlFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// marshal model, format is yaml or json
+func (m *Model) Marshal(yamlFormat bool) ([]byte, error) {
+	if yamlFormat {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err := encoder.Encode(m)
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Close()
+		return buf.Bytes(), err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// save model to file, format is chosen by extension, model is printed as json if filename is -
+func SaveModel(out Output, filename string, m *Model) error {
+	data, err := m.Marshal(isYamlFile(filename))
+	if err != nil {
+		return err
+	}
+	if filename == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return out.WriteFile(filename, data)
+}
+
+// load model from file, format is chosen by extension
+func LoadModel(filename string) (*Model, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	model := &Model{}
+	if isYamlFile(filename) {
+		err = yaml.Unmarshal(data, model)
+	} else {
+		err = json.Unmarshal(data, model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse model %s failed, err: %v", filename, err)
+	}
+	err = model.Check()
+	if err != nil {
+		return nil, fmt.Errorf("invalid model %s, err: %v", filename, err)
+	}
+	return model, nil
+}
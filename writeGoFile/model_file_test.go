@@ -0,0 +1,50 @@
+package writeGoFile
+
+import (
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	C "gopkg.in/check.v1"
+)
+
+type modelSuite struct{}
+
+var _ = C.Suite(&modelSuite{})
+
+// proxy code generated from saved model must be the same as code generated from source
+func (*modelSuite) TestModelRoundTrip(c *C.C) {
+	tmpDir := c.MkDir()
+	for _, name := range []string{"accounts", "multi"} {
+		fSet := token.NewFileSet()
+		imp := loadStubs(c, fSet)
+		dir := filepath.Join("testdata", name)
+		pkg, objects := loadObjects(c, fSet, imp, dir)
+		golden, err := os.ReadFile(filepath.Join(dir, "auto.go.golden"))
+		c.Assert(err, C.IsNil)
+
+		for _, ext := range []string{".json", ".yaml"} {
+			filename := filepath.Join(tmpDir, name+ext)
+			c.Assert(SaveModel(DiskOutput{}, filename, NewModel(objects)), C.IsNil)
+			model, err := LoadModel(filename)
+			c.Assert(err, C.IsNil)
+			c.Check(model.Version, C.Equals, ModelVersion)
+
+			formatted, err := format.Source(generateProxy(c, pkg, model.DBusObjects()))
+			c.Assert(err, C.IsNil)
+			c.Check(string(formatted), C.Equals, string(golden), C.Commentf("model %s", filename))
+		}
+	}
+}
+
+func (*modelSuite) TestModelVersion(c *C.C) {
+	filename := filepath.Join(c.MkDir(), "model.json")
+	c.Assert(os.WriteFile(filename, []byte(`{"version": 2, "services": []}`), 0644), C.IsNil)
+	_, err := LoadModel(filename)
+	c.Check(err, C.ErrorMatches, ".*newer than supported.*")
+
+	c.Assert(os.WriteFile(filename, []byte(`{"services": []}`), 0644), C.IsNil)
+	_, err = LoadModel(filename)
+	c.Check(err, C.ErrorMatches, ".*version is missing.*")
+}
@@ -0,0 +1,85 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"sort"
+)
+
+// order of objects and members in generated files
+const (
+	// objects are sorted by service, path and interface, members by name
+	OrderName = "name"
+	// objects and members are sorted by position of declaration
+	OrderSource = "source"
+)
+
+// sort objects and their members, sort is stable so objects or members at the same position
+// keep the order they are found in, ones without position follow others
+func SortDBusObjects(objects []*DBusObject, order string) error {
+	switch order {
+	case OrderName:
+		sort.SliceStable(objects, func(i, j int) bool {
+			oi, oj := objects[i], objects[j]
+			if oi.serviceName != oj.serviceName {
+				return oi.serviceName < oj.serviceName
+			}
+			if oi.busPath != oj.busPath {
+				return oi.busPath < oj.busPath
+			}
+			return oi.interfaceName < oj.interfaceName
+		})
+		for _, object := range objects {
+			sort.SliceStable(object.methods, func(i, j int) bool {
+				return object.methods[i].Name < object.methods[j].Name
+			})
+			sort.SliceStable(object.signals, func(i, j int) bool {
+				return object.signals[i].Name < object.signals[j].Name
+			})
+			sort.SliceStable(object.properties, func(i, j int) bool {
+				return object.properties[i].Name < object.properties[j].Name
+			})
+		}
+	case OrderSource:
+		sort.SliceStable(objects, func(i, j int) bool {
+			return positionLess(getSourcePosition(objects[i]), getSourcePosition(objects[j]))
+		})
+		for _, object := range objects {
+			sort.SliceStable(object.methods, func(i, j int) bool {
+				return positionLess(object.methods[i].Position, object.methods[j].Position)
+			})
+			sort.SliceStable(object.signals, func(i, j int) bool {
+				return positionLess(object.signals[i].Position, object.signals[j].Position)
+			})
+			sort.SliceStable(object.properties, func(i, j int) bool {
+				return positionLess(object.properties[i].Position, object.properties[j].Position)
+			})
+		}
+	default:
+		return fmt.Errorf("unknown order %q, it should be %s or %s", order, OrderName, OrderSource)
+	}
+	for _, object := range objects {
+		object.order = order
+	}
+	return nil
+}
+
+func getSourcePosition(object *DBusObject) *Position {
+	if object.source == nil {
+		return nil
+	}
+	return object.source.Position
+}
+
+// compare positions, unknown position is after any known position
+func positionLess(pi *Position, pj *Position) bool {
+	if pi == nil || pj == nil {
+		return pi != nil
+	}
+	if pi.File != pj.File {
+		return pi.File < pj.File
+	}
+	if pi.Line != pj.Line {
+		return pi.Line < pj.Line
+	}
+	return pi.Column < pj.Column
+}
@@ -0,0 +1,76 @@
+package writeGoFile
+
+import (
+	C "gopkg.in/check.v1"
+)
+
+type orderSuite struct{}
+
+var _ = C.Suite(&orderSuite{})
+
+func newOrderObject(itfName string, line int, methods ...*DBusMethod) *DBusObject {
+	object := newTestObject("com.deepin.Test", "/com/deepin/Test", itfName)
+	object.source.Position.Line = line
+	object.methods = methods
+	return object
+}
+
+func getMethodNames(object *DBusObject) []string {
+	var names []string
+	for _, method := range object.methods {
+		names = append(names, method.Name)
+	}
+	return names
+}
+
+func (*orderSuite) TestSortDBusObjects(c *C.C) {
+	newObjects := func() []*DBusObject {
+		return []*DBusObject{
+			newOrderObject("com.deepin.Test.B", 1,
+				&DBusMethod{Name: "Reset", Position: &Position{File: "test.go", Line: 20}},
+				&DBusMethod{Name: "Get", Position: &Position{File: "test.go", Line: 30}},
+				&DBusMethod{Name: "Apply"},
+				&DBusMethod{Name: "Set", Position: &Position{File: "test.go", Line: 10}}),
+			newOrderObject("com.deepin.Test.A", 40),
+		}
+	}
+
+	objects := newObjects()
+	c.Assert(SortDBusObjects(objects, OrderName), C.IsNil)
+	c.Check(objects[0].GetInterfaceName(), C.Equals, "com.deepin.Test.A")
+	c.Check(getMethodNames(objects[1]), C.DeepEquals, []string{"Apply", "Get", "Reset", "Set"})
+
+	objects = newObjects()
+	c.Assert(SortDBusObjects(objects, OrderSource), C.IsNil)
+	c.Check(objects[0].GetInterfaceName(), C.Equals, "com.deepin.Test.B")
+	c.Check(getMethodNames(objects[0]), C.DeepEquals, []string{"Set", "Reset", "Get", "Apply"})
+
+	c.Check(SortDBusObjects(objects, "random"), C.NotNil)
+}
+
+// options which change generated files change hash
+func (*orderSuite) TestInputHash(c *C.C) {
+	newObjects := func() []*DBusObject {
+		return []*DBusObject{newOrderObject("com.deepin.Test", 1, &DBusMethod{Name: "Reset"})}
+	}
+	hash := InputHash(newObjects())
+	c.Check(InputHash(newObjects()), C.Equals, hash)
+
+	hashes := map[string]bool{hash: true}
+	for _, fix := range []func(object *DBusObject){
+		func(object *DBusObject) { object.fixedTypeName = "Test" },
+		func(object *DBusObject) { object.fixedObjectName = "test" },
+		func(object *DBusObject) { object.outputDir = "test" },
+		func(object *DBusObject) { object.methods[0].Annotations = []*DBusAnnotation{{Name: "a", Value: "b"}} },
+	} {
+		objects := newObjects()
+		fix(objects[0])
+		hashes[InputHash(objects)] = true
+	}
+	for _, order := range []string{OrderName, OrderSource} {
+		objects := newObjects()
+		c.Assert(SortDBusObjects(objects, order), C.IsNil)
+		hashes[InputHash(objects)] = true
+	}
+	c.Check(hashes, C.HasLen, 7)
+}
@@ -0,0 +1,111 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// comment of generated files, go tools treat files with it as generated
+const GeneratedComment = "Code generated by deepinAutoWrite. DO NOT EDIT."
+
+// get hash of inputs of generated file, api of objects and options which change generated
+// files are hashed, so hash is the same if they are the same, no matter where objects are declared
+func InputHash(objects []*DBusObject) string {
+	h := sha256.New()
+	for _, object := range objects {
+		fmt.Fprintf(h, "object %s %s %s %s\n", object.serviceName, object.busType, object.busPath,
+			object.interfaceName)
+		// options are hashed only if set, so hash of api without options keeps the same
+		if object.order != "" || object.fixedTypeName != "" || object.fixedObjectName != "" ||
+			object.outputDir != "" {
+			fmt.Fprintf(h, "options %q %q %q %q\n", object.order, object.fixedTypeName, object.fixedObjectName,
+				object.outputDir)
+		}
+		writeHashAnnotations(h, object.annotations)
+		for _, method := range object.methods {
+			fmt.Fprintf(h, "method %s %s %s\n", method.Name, getHashArgs(method.In), getHashArgs(method.Out))
+			writeHashAnnotations(h, method.Annotations)
+		}
+		for _, signal := range object.signals {
+			fmt.Fprintf(h, "signal %s %s\n", signal.Name, getHashArgs(signal.Args))
+			writeHashAnnotations(h, signal.Annotations)
+		}
+		for _, prop := range object.properties {
+			fmt.Fprintf(h, "property %s %s %s %s\n", prop.Name, prop.Type, prop.Access, prop.Emit)
+			writeHashAnnotations(h, prop.Annotations)
+		}
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+func getHashArgs(args []*DBusArg) string {
+	var items []string
+	for _, arg := range args {
+		items = append(items, arg.Name+":"+arg.Type)
+	}
+	return "(" + strings.Join(items, ",") + ")"
+}
+
+// annotations follow interface or member they belong to
+func writeHashAnnotations(w io.Writer, annotations []*DBusAnnotation) {
+	for _, annotation := range annotations {
+		fmt.Fprintf(w, "annotation %q %q\n", annotation.Name, annotation.Value)
+	}
+}
+
+// Output receives generated files
+type Output interface {
+	WriteFile(filename string, data []byte) error
+}
+
+// DiskOutput writes generated files to disk, dirs are created if not exist
+type DiskOutput struct{}
+
+func (DiskOutput) WriteFile(filename string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// CheckOutput compares generated files with files on disk byte by byte, nothing is written,
+// unified diff of stale files is written to w
+type CheckOutput struct {
+	w     io.Writer
+	stale []string
+}
+
+func NewCheckOutput(w io.Writer) *CheckOutput {
+	return &CheckOutput{
+		w: w,
+	}
+}
+
+func (c *CheckOutput) WriteFile(filename string, data []byte) error {
+	oldName := filename
+	old, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		oldName = "/dev/null"
+	} else if err != nil {
+		return err
+	}
+	if bytes.Equal(old, data) {
+		return nil
+	}
+	c.stale = append(c.stale, filename)
+	_, err = fmt.Fprint(c.w, UnifiedDiff(oldName, filename, old, data))
+	return err
+}
+
+// get files which are different from generated ones
+func (c *CheckOutput) GetStaleFiles() []string {
+	return c.stale
+}
@@ -0,0 +1,174 @@
+package writeGoFile
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+// SARIF 2.1.0 log, only fields used by diagnostics are declared,
+// see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver *sarifDriver `json:"driver"`
+	} `json:"tool"`
+	// go columns are counted in bytes, they are converted, see columnConverter
+	ColumnKind string         `json:"columnKind"`
+	Results    []*sarifResult `json:"results"`
+}
+
+type sarifDriver struct {
+	Name  string       `json:"name"`
+	Rules []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    *sarifMessage     `json:"message"`
+	Locations  []*sarifLocation  `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			Uri       string `json:"uri"`
+			UriBaseId string `json:"uriBaseId,omitempty"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// base of relative file names, viewers resolve it to root of source
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+// level of SARIF result, info is note in SARIF
+func sarifLevel(severity string) string {
+	if severity == SeverityInfo {
+		return "note"
+	}
+	return severity
+}
+
+// write diagnostics as SARIF log, every reason code is a rule, and service, path and interface
+// of diagnostic are properties of result
+func writeSarif(w io.Writer, diagnostics []*Diagnostic) error {
+	driver := &sarifDriver{
+		Name:  "deepinAutoWrite",
+		Rules: []*sarifRule{},
+	}
+	// rules are sorted by id, so log is the same for the same diagnostics
+	var codes []string
+	ruleIndex := make(map[string]int)
+	for _, d := range diagnostics {
+		if _, ok := ruleIndex[d.Code]; !ok {
+			ruleIndex[d.Code] = 0
+			codes = append(codes, d.Code)
+		}
+	}
+	sort.Strings(codes)
+	for index, code := range codes {
+		ruleIndex[code] = index
+		rule := &sarifRule{Id: code}
+		if description, ok := CodeDescriptions[code]; ok {
+			rule.ShortDescription = &sarifMessage{Text: description}
+		}
+		driver.Rules = append(driver.Rules, rule)
+	}
+
+	run := &sarifRun{
+		ColumnKind: "unicodeCodePoints",
+		Results:    []*sarifResult{},
+	}
+	run.Tool.Driver = driver
+	columns := newColumnConverter()
+	for _, d := range diagnostics {
+		result := &sarifResult{
+			RuleId:    d.Code,
+			RuleIndex: ruleIndex[d.Code],
+			Level:     sarifLevel(d.Severity),
+			Message:   &sarifMessage{Text: d.describe()},
+		}
+		if location := getSarifLocation(d.Position, columns); location != nil {
+			result.Locations = []*sarifLocation{location}
+		}
+		for key, value := range map[string]string{"service": d.Service, "path": d.Path, "interface": d.Interface} {
+			if value == "" {
+				continue
+			}
+			if result.Properties == nil {
+				result.Properties = make(map[string]string)
+			}
+			result.Properties[key] = value
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	data, err := json.MarshalIndent(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// location of position, relative file is relative to source root, nil if position is unknown,
+// columns are left out if file can not be read to convert them
+func getSarifLocation(position *Position, columns *columnConverter) *sarifLocation {
+	if position == nil {
+		return nil
+	}
+	location := &sarifLocation{}
+	artifact := &location.PhysicalLocation.ArtifactLocation
+	uri := &url.URL{Path: filepath.ToSlash(position.File)}
+	if filepath.IsAbs(position.File) {
+		uri.Scheme = "file"
+	} else {
+		artifact.UriBaseId = sarifSrcRoot
+	}
+	artifact.Uri = uri.String()
+	region := &sarifRegion{
+		StartLine: position.Line,
+		EndLine:   position.EndLine,
+	}
+	region.StartColumn = columns.convert(position.File, position.Line, position.Column)
+	if region.StartColumn != 0 {
+		region.EndColumn = columns.convert(position.File, position.EndLine, position.EndColumn)
+	}
+	location.PhysicalLocation.Region = region
+	return location
+}
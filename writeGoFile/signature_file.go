@@ -0,0 +1,269 @@
+package writeGoFile
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// godbus package path, the v5 module path is also accepted
+const (
+	godbusPkgPath   = "github.com/godbus/dbus"
+	godbusV5PkgPath = "github.com/godbus/dbus/v5"
+)
+
+// signature of special godbus types
+var godbusTypeSignature = map[string]string{
+	"Variant":     "v",
+	"ObjectPath":  "o",
+	"Signature":   "g",
+	"UnixFD":      "h",
+	"UnixFDIndex": "h",
+}
+
+// SignatureError is returned when go type can not be marshaled to D-Bus
+type SignatureError struct {
+	Type   types.Type
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("dbus: invalid type %s: %s", e.Type.String(), e.Reason)
+}
+
+// get D-Bus signature of go type, same as godbus SignatureOf
+func SignatureOf(ty types.Type) (string, error) {
+	return getSignature(ty, make(map[types.Type]bool))
+}
+
+// get D-Bus signature of go type, visiting records named types to stop recursive types
+func getSignature(ty types.Type, visiting map[types.Type]bool) (string, error) {
+	switch t := ty.(type) {
+	case *types.Basic:
+		return getBasicSignature(t)
+	case *types.Named:
+		// check if is godbus special type
+		if sig, ok := getGodbusSignature(t); ok {
+			return sig, nil
+		}
+		if visiting[t] {
+			return "", &SignatureError{Type: t, Reason: "recursive type"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return getSignature(t.Underlying(), visiting)
+	case *types.Alias:
+		return getSignature(types.Unalias(t), visiting)
+	case *types.Pointer:
+		return getSignature(t.Elem(), visiting)
+	case *types.Slice:
+		elem, err := getSignature(t.Elem(), visiting)
+		if err != nil {
+			return "", err
+		}
+		return "a" + elem, nil
+	case *types.Array:
+		elem, err := getSignature(t.Elem(), visiting)
+		if err != nil {
+			return "", err
+		}
+		return "a" + elem, nil
+	case *types.Map:
+		if !isKeyType(t.Key()) {
+			return "", &SignatureError{Type: t, Reason: "map key must be a basic type"}
+		}
+		key, err := getSignature(t.Key(), visiting)
+		if err != nil {
+			return "", err
+		}
+		elem, err := getSignature(t.Elem(), visiting)
+		if err != nil {
+			return "", err
+		}
+		return "a{" + key + elem + "}", nil
+	case *types.Struct:
+		var sig string
+		for fIndex := 0; fIndex < t.NumFields(); fIndex++ {
+			field := t.Field(fIndex)
+			// unexported fields and fields with dbus:"-" tag are ignored by godbus
+			if !field.Exported() || reflect.StructTag(t.Tag(fIndex)).Get("dbus") == "-" {
+				continue
+			}
+			fieldSig, err := getSignature(field.Type(), visiting)
+			if err != nil {
+				return "", err
+			}
+			sig += fieldSig
+		}
+		// empty struct is not allowed by D-Bus
+		if sig == "" {
+			return "", &SignatureError{Type: t, Reason: "struct has no exported fields"}
+		}
+		return "(" + sig + ")", nil
+	case *types.Interface:
+		return "v", nil
+	case *types.Chan:
+		return "", &SignatureError{Type: t, Reason: "channel can not be marshaled"}
+	case *types.Signature:
+		return "", &SignatureError{Type: t, Reason: "func can not be marshaled"}
+	case *types.TypeParam:
+		return "", &SignatureError{Type: t, Reason: "type parameter can not be marshaled"}
+	}
+	return "", &SignatureError{Type: ty, Reason: "unsupported type"}
+}
+
+// get D-Bus signature of basic type
+func getBasicSignature(basic *types.Basic) (string, error) {
+	switch basic.Kind() {
+	case types.Uint8:
+		return "y", nil
+	case types.Bool:
+		return "b", nil
+	case types.Int16:
+		return "n", nil
+	case types.Uint16:
+		return "q", nil
+	case types.Int, types.Int32:
+		return "i", nil
+	case types.Uint, types.Uint32:
+		return "u", nil
+	case types.Int64:
+		return "x", nil
+	case types.Uint64:
+		return "t", nil
+	case types.Float64:
+		return "d", nil
+	case types.String:
+		return "s", nil
+	case types.Invalid:
+		return "", &SignatureError{Type: basic, Reason: "type can not be resolved"}
+	}
+	return "", &SignatureError{Type: basic, Reason: "basic type has no D-Bus equivalent"}
+}
+
+// get signature of godbus special type
+func getGodbusSignature(named *types.Named) (string, bool) {
+	obj := named.Obj()
+	if obj == nil || obj.Pkg() == nil {
+		return "", false
+	}
+	if !isGodbusPkg(obj.Pkg().Path()) {
+		return "", false
+	}
+	sig, ok := godbusTypeSignature[obj.Name()]
+	return sig, ok
+}
+
+// check if type is godbus type of name, pointer is dereferenced
+func isGodbusType(ty types.Type, name string) bool {
+	if pointer, ok := ty.(*types.Pointer); ok {
+		ty = pointer.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == name && isGodbusPkg(named.Obj().Pkg().Path())
+}
+
+// check if package path is godbus
+func isGodbusPkg(pkgPath string) bool {
+	return pkgPath == godbusPkgPath || pkgPath == godbusV5PkgPath
+}
+
+// check if type can be used as map key, same as godbus isKeyType
+func isKeyType(ty types.Type) bool {
+	basic, ok := ty.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch basic.Kind() {
+	case types.Uint8, types.Int16, types.Int32, types.Int64, types.Float64,
+		types.Uint16, types.Uint32, types.Uint64, types.String,
+		types.Uint, types.Int:
+		return true
+	}
+	return false
+}
+
+// go type of basic D-Bus signature used in generated code
+var signatureGoTypeMap = map[byte]string{
+	'y': "byte",
+	'b': "bool",
+	'n': "int16",
+	'q': "uint16",
+	'i': "int32",
+	'u': "uint32",
+	'x': "int64",
+	't': "uint64",
+	'd': "float64",
+	's': "string",
+	'o': "dbus.ObjectPath",
+	'g': "dbus.Signature",
+	'h': "dbus.UnixFD",
+	'v': "dbus.Variant",
+}
+
+// get go type of single complete D-Bus signature, types are written as generated code refers them,
+// struct is converted to anonymous struct with exported fields
+func GoTypeOf(sig string) (string, error) {
+	goType, rest, err := parseGoType(sig)
+	if err != nil {
+		return "", err
+	}
+	if rest != "" {
+		return "", fmt.Errorf("signature %q is not single complete type", sig)
+	}
+	return goType, nil
+}
+
+// parse first complete type of signature, rest of signature is returned
+func parseGoType(sig string) (string, string, error) {
+	if sig == "" {
+		return "", "", errors.New("signature is empty")
+	}
+	if goType, ok := signatureGoTypeMap[sig[0]]; ok {
+		return goType, sig[1:], nil
+	}
+	switch sig[0] {
+	case 'a':
+		// dict
+		if len(sig) > 1 && sig[1] == '{' {
+			key, rest, err := parseGoType(sig[2:])
+			if err != nil {
+				return "", "", err
+			}
+			elem, rest, err := parseGoType(rest)
+			if err != nil {
+				return "", "", err
+			}
+			if rest == "" || rest[0] != '}' {
+				return "", "", fmt.Errorf("dict entry of %q is not closed", sig)
+			}
+			return "map[" + key + "]" + elem, rest[1:], nil
+		}
+		elem, rest, err := parseGoType(sig[1:])
+		if err != nil {
+			return "", "", err
+		}
+		return "[]" + elem, rest, nil
+	case '(':
+		var fields []string
+		rest := sig[1:]
+		for rest != "" && rest[0] != ')' {
+			field, next, err := parseGoType(rest)
+			if err != nil {
+				return "", "", err
+			}
+			fields = append(fields, fmt.Sprintf("Field%d %s", len(fields), field))
+			rest = next
+		}
+		if rest == "" {
+			return "", "", fmt.Errorf("struct of %q is not closed", sig)
+		}
+		return "struct {" + strings.Join(fields, "; ") + "}", rest[1:], nil
+	}
+	return "", "", fmt.Errorf("invalid signature %q", sig)
+}
@@ -0,0 +1,157 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	C "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { C.TestingT(t) }
+
+type signatureSuite struct{}
+
+func init() {
+	C.Suite(&signatureSuite{})
+}
+
+// godbus types used by test code
+const godbusStubCode = `
+package dbus
+
+type Variant struct {
+	sig   Signature
+	value interface{}
+}
+
+type Signature struct {
+	str string
+}
+
+type ObjectPath string
+
+type UnixFD int32
+
+type UnixFDIndex uint32
+`
+
+// map importer, package is checked from source
+type sourceImporter map[string]*types.Package
+
+func (v sourceImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := v[path]; ok {
+		return pkg, nil
+	}
+	return importer.Default().Import(path)
+}
+
+// type check test code, godbus package is checked from stub code
+func checkTestCode(c *C.C, code string) *types.Package {
+	fSet := token.NewFileSet()
+	imp := make(sourceImporter)
+	dbusFile, err := parser.ParseFile(fSet, "dbus.go", godbusStubCode, 0)
+	c.Assert(err, C.IsNil)
+	dbusPkg, err := (&types.Config{}).Check(godbusPkgPath, fSet, []*ast.File{dbusFile}, nil)
+	c.Assert(err, C.IsNil)
+	imp[godbusPkgPath] = dbusPkg
+
+	f, err := parser.ParseFile(fSet, "test.go", code, 0)
+	c.Assert(err, C.IsNil)
+	pkg, err := (&types.Config{Importer: imp}).Check("test", fSet, []*ast.File{f}, nil)
+	c.Assert(err, C.IsNil)
+	return pkg
+}
+
+func (*signatureSuite) TestSignatureOf(c *C.C) {
+	pkg := checkTestCode(c, `
+package test
+
+import "github.com/godbus/dbus"
+
+type Point struct {
+	X, Y int32
+	name string
+	Skip bool `+"`dbus:\"-\"`"+`
+}
+
+type Paths []dbus.ObjectPath
+
+var (
+	vByte    byte
+	vBool    bool
+	vInt16   int16
+	vUint16  uint16
+	vInt     int
+	vUint32  uint32
+	vInt64   int64
+	vUint64  uint64
+	vDouble  float64
+	vString  string
+	vPath    dbus.ObjectPath
+	vPaths   Paths
+	vVariant dbus.Variant
+	vSig     dbus.Signature
+	vFD      dbus.UnixFD
+	vPoint   *Point
+	vMap     map[string]dbus.Variant
+	vNested  map[uint32][]Point
+	vAny     interface{}
+)
+`)
+	expected := map[string]string{
+		"vByte":    "y",
+		"vBool":    "b",
+		"vInt16":   "n",
+		"vUint16":  "q",
+		"vInt":     "i",
+		"vUint32":  "u",
+		"vInt64":   "x",
+		"vUint64":  "t",
+		"vDouble":  "d",
+		"vString":  "s",
+		"vPath":    "o",
+		"vPaths":   "ao",
+		"vVariant": "v",
+		"vSig":     "g",
+		"vFD":      "h",
+		"vPoint":   "(ii)",
+		"vMap":     "a{sv}",
+		"vNested":  "a{ua(ii)}",
+		"vAny":     "v",
+	}
+	for name, sig := range expected {
+		result, err := SignatureOf(pkg.Scope().Lookup(name).Type())
+		c.Check(err, C.IsNil)
+		c.Check(result, C.Equals, sig, C.Commentf("var %s", name))
+	}
+}
+
+func (*signatureSuite) TestSignatureOfInvalid(c *C.C) {
+	pkg := checkTestCode(c, `
+package test
+
+type List struct {
+	Next *List
+}
+
+var (
+	vChan    chan int
+	vFunc    func()
+	vFloat32 float32
+	vMapKey  map[[2]int]string
+	vBoolKey map[bool]int
+	vList    List
+	vEmpty   struct{ name string }
+)
+`)
+	for _, name := range []string{"vChan", "vFunc", "vFloat32", "vMapKey", "vBoolKey", "vList", "vEmpty"} {
+		_, err := SignatureOf(pkg.Scope().Lookup(name).Type())
+		c.Check(err, C.NotNil, C.Commentf("var %s", name))
+		_, ok := err.(*SignatureError)
+		c.Check(ok, C.Equals, true)
+	}
+}
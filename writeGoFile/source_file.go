@@ -3,10 +3,11 @@ package writeGoFile
 import (
 	"bytes"
 	"fmt"
+	"go/format"
+	"go/token"
 	"io"
-	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -15,6 +16,8 @@ type SourceFile struct {
 	Pkg       string
 	GoImports []string
 	GoBody    *SourceBody
+	// hash of inputs written in generated comment
+	InputHash string
 }
 
 // source file
@@ -26,32 +29,142 @@ func NewSourceFile(pkg string) *SourceFile {
 	return sf
 }
 
-func (v *SourceFile) Print() error {
-	_, err := v.WriteTo(os.Stdout)
-	return err
+// proxy file name in package dir, same as go-dbus-factory
+const proxyFileName = "auto.go"
+
+// get package dir and package name of service, e.g. com.deepin.daemon.Accounts =>
+// com.deepin.daemon.accounts and accounts
+func GetServicePackage(serviceName string) (string, string) {
+	dir := strings.ToLower(serviceName)
+	pkg := toIdentifier(lastElem(dir, "."))
+	if pkg == "" || token.IsKeyword(pkg) {
+		pkg = "proxy" + pkg
+	}
+	return dir, pkg
+}
+
+// save proxy code of objects to outDir, one package for each service, or for each
+// output dir set by config, saved file names are returned
+func SaveProxyFiles(out Output, outDir string, objects []*DBusObject) ([]string, error) {
+	dirs, dirObjects := groupByOutputDir(objects)
+	var filenames []string
+	for _, dir := range dirs {
+		sf := NewProxySourceFile(getDirPackage(dir))
+		sf.WriteDBusObjects(dirObjects[dir])
+		filename := filepath.Join(outDir, dir, proxyFileName)
+		err := sf.Save(out, filename)
+		if err != nil {
+			return filenames, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// group objects by dir of generated files relative to out dir, it is service dir or dir set
+// by config, dirs are kept in order of services, objects with unknown service are skipped
+func groupByOutputDir(objects []*DBusObject) ([]string, map[string][]*DBusObject) {
+	services, serviceObjects := groupByService(objects)
+	var dirs []string
+	dirObjects := make(map[string][]*DBusObject)
+	for _, service := range services {
+		serviceDir, _ := GetServicePackage(service)
+		for _, object := range serviceObjects[service] {
+			dir := serviceDir
+			if object.outputDir != "" {
+				dir = object.outputDir
+			}
+			if _, ok := dirObjects[dir]; !ok {
+				dirs = append(dirs, dir)
+			}
+			dirObjects[dir] = append(dirObjects[dir], object)
+		}
+	}
+	return dirs, dirObjects
+}
+
+// get package name of dir, same as package of service if dir is service dir
+func getDirPackage(dir string) string {
+	_, pkg := GetServicePackage(filepath.Base(dir))
+	return pkg
+}
+
+// group objects by service name, services are kept in order they appear,
+// objects with unknown service are skipped
+func groupByService(objects []*DBusObject) ([]string, map[string][]*DBusObject) {
+	var services []string
+	serviceObjects := make(map[string][]*DBusObject)
+	for _, object := range objects {
+		if object.serviceName == "" {
+			object.report(nil, SeverityWarning, CodeUnknownService,
+				"skip object, reason: service name is unknown")
+			continue
+		}
+		if _, ok := serviceObjects[object.serviceName]; !ok {
+			services = append(services, object.serviceName)
+		}
+		serviceObjects[object.serviceName] = append(serviceObjects[object.serviceName], object)
+	}
+	return services, serviceObjects
+}
+
+// source file of proxy code, imports used by generated code are added
+func NewProxySourceFile(pkg string) *SourceFile {
+	sf := NewSourceFile(pkg)
+
+	sf.AddGoImport("errors")
+	sf.AddGoImport("fmt")
+	sf.AddGoImport("unsafe")
+	sf.AddGoImport("github.com/godbus/dbus")
+	sf.AddGoImport(DBusUtilPath)
+	sf.AddGoImport(DBusUtilPath + "/proxy")
+
+	sf.GoBody.Pn("/* prevent compile error */")
+	sf.GoBody.Pn("var _ = errors.New")
+	sf.GoBody.Pn("var _ dbusutil.SignalHandlerId")
+	sf.GoBody.Pn("var _ = fmt.Sprintf")
+	sf.GoBody.Pn("var _ unsafe.Pointer")
+	sf.GoBody.Pn("")
+	return sf
 }
 
-func (v *SourceFile) Save(filename string) {
-	f, err := os.Create(filename)
+// print formatted source, it is the same as source saved
+func (v *SourceFile) Print() error {
+	src, err := v.Format()
 	if err != nil {
-		log.Fatal("fail to create file:", err)
+		return err
 	}
-	defer f.Close()
-	_, err = v.WriteTo(f)
+	_, err = os.Stdout.Write(src)
+	return err
+}
+
+// save formatted source to file
+func (v *SourceFile) Save(out Output, filename string) error {
+	src, err := v.Format()
 	if err != nil {
-		log.Fatal("failed to write to file:", err)
+		return fmt.Errorf("failed to format file %s: %v", filename, err)
 	}
+	return out.WriteFile(filename, src)
+}
 
-	out, err := exec.Command("go", "fmt", filename).CombinedOutput()
+// get source formatted by gofmt
+func (v *SourceFile) Format() ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := v.WriteTo(&buf)
 	if err != nil {
-		log.Printf("%s", out)
-		log.Fatal("failed to format file:", filename)
+		return nil, err
 	}
+	return format.Source(buf.Bytes())
 }
 
 func (v *SourceFile) WriteTo(w io.Writer) (n int64, err error) {
 	var wn int
-	wn, err = io.WriteString(w, "package "+v.Pkg+"\n")
+	// generated comment must be before package clause and not be doc of package
+	header := "// " + GeneratedComment + "\n"
+	if v.InputHash != "" {
+		header += "// input hash: " + v.InputHash + "\n"
+	}
+	wn, err = io.WriteString(w, header+"\n"+"package "+v.Pkg+"\n")
 	n += int64(wn)
 	if err != nil {
 		return
@@ -109,25 +222,35 @@ func (v *SourceBody) P(format string, a ...interface{}) {
 	v.writeStr(str)
 }
 
+// write proxy code of objects, hash of objects is recorded
+func (v *SourceFile) WriteDBusObjects(objects []*DBusObject) {
+	v.InputHash = InputHash(objects)
+	v.GoBody.WriteDBusObjects(objects)
+}
+
 func (v *SourceBody) WriteDBusObjects(objects []*DBusObject) {
-	for _, object := range objects {
-		writeStruct(v, object)
-		writeNewObject(v, object)
-		writeImplementerMethods(v, object)
+	for _, proxy := range GroupDBusObjects(objects) {
+		writeStruct(v, proxy)
+		writeNewObject(v, proxy)
+		writeInterfaceAccessors(v, proxy)
 
-		// write method
-		for _, method := range object.methods {
-			writeMethod(v, object.ObjectName, method)
-		}
+		for _, object := range proxy.interfaces {
+			writeImplementerMethods(v, object)
 
-		// write signal
-		for _, signal := range object.signals {
-			writeSignal(v, object.ObjectName, signal)
-		}
+			// write method
+			for _, method := range object.methods {
+				writeMethod(v, object, method)
+			}
+
+			// write signal
+			for _, signal := range object.signals {
+				writeSignal(v, object, signal)
+			}
 
-		// write property
-		for _, property := range object.properties {
-			writeProperty(v, object.ObjectName, property)
+			// write property
+			for _, property := range object.properties {
+				writeProperty(v, object, property)
+			}
 		}
 	}
 }
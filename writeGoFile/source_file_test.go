@@ -0,0 +1,149 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+
+	C "gopkg.in/check.v1"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files of generated code")
+
+type sourceSuite struct{}
+
+func init() {
+	C.Suite(&sourceSuite{})
+}
+
+// stub packages in testdata, listed in dependency order
+var stubPackages = []struct {
+	path string
+	dir  string
+}{
+	{godbusPkgPath, "dbus"},
+	{"pkg.deepin.io/lib/dbusutil", "dbusutil"},
+	{"pkg.deepin.io/lib/dbusutil/proxy", "proxy"},
+}
+
+// parse all go files in dir
+func parseDir(c *C.C, fSet *token.FileSet, dir string) []*ast.File {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	c.Assert(err, C.IsNil)
+	sort.Strings(filenames)
+	var files []*ast.File
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(fSet, filename, nil, parser.ParseComments)
+		c.Assert(err, C.IsNil)
+		files = append(files, f)
+	}
+	return files
+}
+
+// type check stub packages, so fixtures and generated code can be checked without dependencies
+func loadStubs(c *C.C, fSet *token.FileSet) sourceImporter {
+	imp := make(sourceImporter)
+	for _, stub := range stubPackages {
+		files := parseDir(c, fSet, filepath.Join("testdata", "stub", stub.dir))
+		pkg, err := (&types.Config{Importer: imp}).Check(stub.path, fSet, files, nil)
+		c.Assert(err, C.IsNil)
+		imp[stub.path] = pkg
+	}
+	return imp
+}
+
+// load bus objects of fixture package, same as the tool does without call graph analysis
+func loadObjects(c *C.C, fSet *token.FileSet, imp sourceImporter, dir string) (string, []*DBusObject) {
+	files := parseDir(c, fSet, dir)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	pkg, err := (&types.Config{Importer: imp}).Check(filepath.Base(dir), fSet, files, info)
+	c.Assert(err, C.IsNil)
+
+	container := NewDBusContainer()
+	for _, file := range files {
+		elems, unresolved := GetDBusPathName(fSet, file, info)
+		container.AddDBusElem(elems...)
+		for _, u := range unresolved {
+			u.Report()
+		}
+	}
+	container.RefreshDBusObj(files)
+	container.RefreshDBusPath(files, info)
+	container.RefreshDBusInterface(files, info)
+	tracer := NewServiceTracer(info)
+	tracer.Trace(files)
+	container.RefreshDBusService(tracer)
+
+	implementer := imp["pkg.deepin.io/lib/dbusutil"].Scope().Lookup("Implementer").Type().Underlying().(*types.Interface)
+	var objects []*DBusObject
+	for _, name := range pkg.Scope().Names() {
+		typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !typeName.Exported() {
+			continue
+		}
+		named, ok := typeName.Type().(*types.Named)
+		if !ok || !types.Implements(types.NewPointer(named), implementer) {
+			continue
+		}
+		elem := container.GetDBusElemByObj(name)
+		c.Assert(elem, C.NotNil, C.Commentf("export of %s", name))
+		objects = append(objects, NewDBusObjectFromElem(fSet, elem, named))
+	}
+	return pkg.Name(), objects
+}
+
+// generate proxy code of objects
+func generateProxy(c *C.C, pkg string, objects []*DBusObject) []byte {
+	sf := NewProxySourceFile(pkg)
+	sf.WriteDBusObjects(objects)
+	var buf bytes.Buffer
+	_, err := sf.WriteTo(&buf)
+	c.Assert(err, C.IsNil)
+	return buf.Bytes()
+}
+
+func (*sourceSuite) TestGenerateGolden(c *C.C) {
+	for _, name := range []string{"accounts", "multi"} {
+		fSet := token.NewFileSet()
+		imp := loadStubs(c, fSet)
+		dir := filepath.Join("testdata", name)
+		pkg, objects := loadObjects(c, fSet, imp, dir)
+		code := generateProxy(c, pkg, objects)
+
+		// generated code must be valid go code
+		formatted, err := format.Source(code)
+		c.Assert(err, C.IsNil, C.Commentf("format code of %s:\n%s", name, code))
+
+		goldenFile := filepath.Join(dir, "auto.go.golden")
+		if *updateGolden {
+			c.Assert(os.WriteFile(goldenFile, formatted, 0644), C.IsNil)
+		}
+		golden, err := os.ReadFile(goldenFile)
+		c.Assert(err, C.IsNil)
+		c.Check(string(formatted), C.Equals, string(golden), C.Commentf("golden of %s", name))
+
+		// printed and saved code are formatted in the same way
+		sf := NewProxySourceFile(pkg)
+		sf.WriteDBusObjects(objects)
+		src, err := sf.Format()
+		c.Assert(err, C.IsNil)
+		c.Check(string(src), C.Equals, string(golden))
+
+		// generated code must compile against dbusutil and proxy
+		f, err := parser.ParseFile(fSet, "auto.go", formatted, 0)
+		c.Assert(err, C.IsNil)
+		_, err = (&types.Config{Importer: imp}).Check(name, fSet, []*ast.File{f}, nil)
+		c.Check(err, C.IsNil, C.Commentf("type check code of %s", name))
+	}
+}
@@ -0,0 +1,48 @@
+package multi
+
+import (
+	"github.com/godbus/dbus"
+	"pkg.deepin.io/lib/dbusutil"
+)
+
+const dbusServiceName = "com.deepin.daemon.Display"
+
+type Display struct {
+	Primary string
+
+	signals *displaySignals
+}
+
+type displaySignals struct {
+	PrimaryChanged struct {
+		primary string
+	}
+}
+
+func (d *Display) GetInterfaceName() string {
+	return dbusServiceName
+}
+
+func (d *Display) Reset() *dbus.Error { return nil }
+
+type Debug struct {
+	Level int32 `prop:"access:rw"`
+}
+
+func (d *Debug) GetInterfaceName() string {
+	return dbusServiceName + ".Debug"
+}
+
+func (d *Debug) Reset() *dbus.Error { return nil }
+
+func Start() error {
+	service, err := dbusutil.NewSessionService()
+	if err != nil {
+		return err
+	}
+	err = service.Export("/com/deepin/daemon/Display", &Display{}, &Debug{})
+	if err != nil {
+		return err
+	}
+	return service.RequestName(dbusServiceName)
+}
@@ -0,0 +1,51 @@
+// stub of github.com/godbus/dbus, only declarations used by fixtures and generated code
+package dbus
+
+type Conn struct{}
+
+type Flags byte
+
+type Call struct {
+	Done chan *Call
+	Err  error
+	Body []interface{}
+}
+
+func (c *Call) Store(retvalues ...interface{}) error { return nil }
+
+type Signal struct {
+	Sender string
+	Path   ObjectPath
+	Name   string
+	Body   []interface{}
+}
+
+type Message struct{}
+
+type Sender string
+
+type Error struct {
+	Name string
+	Body []interface{}
+}
+
+func (e Error) Error() string { return e.Name }
+
+type Variant struct {
+	sig   Signature
+	value interface{}
+}
+
+type Signature struct {
+	str string
+}
+
+type ObjectPath string
+
+func (o ObjectPath) IsValid() bool { return true }
+
+type UnixFD int32
+
+type UnixFDIndex uint32
+
+func Store(src []interface{}, dest ...interface{}) error { return nil }
@@ -0,0 +1,52 @@
+package dbusutil
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus"
+)
+
+type Implementer interface {
+	GetInterfaceName() string
+}
+
+type Service struct {
+	conn *dbus.Conn
+	mu   sync.Mutex
+}
+
+func NewSessionService() (*Service, error) { return &Service{}, nil }
+func NewSystemService() (*Service, error)  { return &Service{}, nil }
+func NewService(conn *dbus.Conn) *Service  { return &Service{conn: conn} }
+
+func (s *Service) Export(path dbus.ObjectPath, implementers ...Implementer) error { return nil }
+func (s *Service) StopExport(v Implementer) error                                 { return nil }
+func (s *Service) RequestName(name string) error                                  { return nil }
+func (s *Service) Conn() *dbus.Conn                                               { return s.conn }
+func (s *Service) Emit(v Implementer, signalName string, values ...interface{}) error {
+	return nil
+}
+func (s *Service) EmitPropertyChanged(v Implementer, propName string, value interface{}) error {
+	return nil
+}
+func (s *Service) Wait() {}
+
+type SignalHandlerId int
+
+type SignalRule struct {
+	Path dbus.ObjectPath
+	Name string
+}
+
+type SignalHandlerFunc func(sig *dbus.Signal)
+
+type PropertyWriteInfo struct {
+	Name  string
+	Value interface{}
+}
+
+type PropertyWriteCallback func(write *PropertyWriteInfo) *dbus.Error
+
+func ToError(err error) *dbus.Error { return nil }
+
+func WriteXML(v Implementer) {}
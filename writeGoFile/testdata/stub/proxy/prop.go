@@ -0,0 +1,205 @@
+package proxy
+
+import "github.com/godbus/dbus"
+
+type PropBool struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropBool) Get(flags dbus.Flags) (value bool, err error)            { return }
+func (p PropBool) Set(flags dbus.Flags, value bool) error                  { return nil }
+func (p PropBool) ConnectChanged(cb func(hasValue bool, value bool)) error { return nil }
+
+type PropBoolArray struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropBoolArray) Get(flags dbus.Flags) (value []bool, err error)            { return }
+func (p PropBoolArray) Set(flags dbus.Flags, value []bool) error                  { return nil }
+func (p PropBoolArray) ConnectChanged(cb func(hasValue bool, value []bool)) error { return nil }
+
+type PropByte struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropByte) Get(flags dbus.Flags) (value byte, err error)            { return }
+func (p PropByte) Set(flags dbus.Flags, value byte) error                  { return nil }
+func (p PropByte) ConnectChanged(cb func(hasValue bool, value byte)) error { return nil }
+
+type PropByteArray struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropByteArray) Get(flags dbus.Flags) (value []byte, err error)            { return }
+func (p PropByteArray) Set(flags dbus.Flags, value []byte) error                  { return nil }
+func (p PropByteArray) ConnectChanged(cb func(hasValue bool, value []byte)) error { return nil }
+
+type PropInt16 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt16) Get(flags dbus.Flags) (value int16, err error)            { return }
+func (p PropInt16) Set(flags dbus.Flags, value int16) error                  { return nil }
+func (p PropInt16) ConnectChanged(cb func(hasValue bool, value int16)) error { return nil }
+
+type PropInt16Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt16Array) Get(flags dbus.Flags) (value []int16, err error)            { return }
+func (p PropInt16Array) Set(flags dbus.Flags, value []int16) error                  { return nil }
+func (p PropInt16Array) ConnectChanged(cb func(hasValue bool, value []int16)) error { return nil }
+
+type PropUint16 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint16) Get(flags dbus.Flags) (value uint16, err error)            { return }
+func (p PropUint16) Set(flags dbus.Flags, value uint16) error                  { return nil }
+func (p PropUint16) ConnectChanged(cb func(hasValue bool, value uint16)) error { return nil }
+
+type PropUint16Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint16Array) Get(flags dbus.Flags) (value []uint16, err error)            { return }
+func (p PropUint16Array) Set(flags dbus.Flags, value []uint16) error                  { return nil }
+func (p PropUint16Array) ConnectChanged(cb func(hasValue bool, value []uint16)) error { return nil }
+
+type PropInt32 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt32) Get(flags dbus.Flags) (value int32, err error)            { return }
+func (p PropInt32) Set(flags dbus.Flags, value int32) error                  { return nil }
+func (p PropInt32) ConnectChanged(cb func(hasValue bool, value int32)) error { return nil }
+
+type PropInt32Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt32Array) Get(flags dbus.Flags) (value []int32, err error)            { return }
+func (p PropInt32Array) Set(flags dbus.Flags, value []int32) error                  { return nil }
+func (p PropInt32Array) ConnectChanged(cb func(hasValue bool, value []int32)) error { return nil }
+
+type PropUint32 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint32) Get(flags dbus.Flags) (value uint32, err error)            { return }
+func (p PropUint32) Set(flags dbus.Flags, value uint32) error                  { return nil }
+func (p PropUint32) ConnectChanged(cb func(hasValue bool, value uint32)) error { return nil }
+
+type PropUint32Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint32Array) Get(flags dbus.Flags) (value []uint32, err error)            { return }
+func (p PropUint32Array) Set(flags dbus.Flags, value []uint32) error                  { return nil }
+func (p PropUint32Array) ConnectChanged(cb func(hasValue bool, value []uint32)) error { return nil }
+
+type PropInt64 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt64) Get(flags dbus.Flags) (value int64, err error)            { return }
+func (p PropInt64) Set(flags dbus.Flags, value int64) error                  { return nil }
+func (p PropInt64) ConnectChanged(cb func(hasValue bool, value int64)) error { return nil }
+
+type PropInt64Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropInt64Array) Get(flags dbus.Flags) (value []int64, err error)            { return }
+func (p PropInt64Array) Set(flags dbus.Flags, value []int64) error                  { return nil }
+func (p PropInt64Array) ConnectChanged(cb func(hasValue bool, value []int64)) error { return nil }
+
+type PropUint64 struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint64) Get(flags dbus.Flags) (value uint64, err error)            { return }
+func (p PropUint64) Set(flags dbus.Flags, value uint64) error                  { return nil }
+func (p PropUint64) ConnectChanged(cb func(hasValue bool, value uint64)) error { return nil }
+
+type PropUint64Array struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropUint64Array) Get(flags dbus.Flags) (value []uint64, err error)            { return }
+func (p PropUint64Array) Set(flags dbus.Flags, value []uint64) error                  { return nil }
+func (p PropUint64Array) ConnectChanged(cb func(hasValue bool, value []uint64)) error { return nil }
+
+type PropDouble struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropDouble) Get(flags dbus.Flags) (value float64, err error)            { return }
+func (p PropDouble) Set(flags dbus.Flags, value float64) error                  { return nil }
+func (p PropDouble) ConnectChanged(cb func(hasValue bool, value float64)) error { return nil }
+
+type PropDoubleArray struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropDoubleArray) Get(flags dbus.Flags) (value []float64, err error)            { return }
+func (p PropDoubleArray) Set(flags dbus.Flags, value []float64) error                  { return nil }
+func (p PropDoubleArray) ConnectChanged(cb func(hasValue bool, value []float64)) error { return nil }
+
+type PropString struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropString) Get(flags dbus.Flags) (value string, err error)            { return }
+func (p PropString) Set(flags dbus.Flags, value string) error                  { return nil }
+func (p PropString) ConnectChanged(cb func(hasValue bool, value string)) error { return nil }
+
+type PropStringArray struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropStringArray) Get(flags dbus.Flags) (value []string, err error)            { return }
+func (p PropStringArray) Set(flags dbus.Flags, value []string) error                  { return nil }
+func (p PropStringArray) ConnectChanged(cb func(hasValue bool, value []string)) error { return nil }
+
+type PropObjectPath struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropObjectPath) Get(flags dbus.Flags) (value dbus.ObjectPath, err error) { return }
+func (p PropObjectPath) Set(flags dbus.Flags, value dbus.ObjectPath) error       { return nil }
+func (p PropObjectPath) ConnectChanged(cb func(hasValue bool, value dbus.ObjectPath)) error {
+	return nil
+}
+
+type PropObjectPathArray struct {
+	Impl Implementer
+	Name string
+}
+
+func (p PropObjectPathArray) Get(flags dbus.Flags) (value []dbus.ObjectPath, err error) { return }
+func (p PropObjectPathArray) Set(flags dbus.Flags, value []dbus.ObjectPath) error       { return nil }
+func (p PropObjectPathArray) ConnectChanged(cb func(hasValue bool, value []dbus.ObjectPath)) error {
+	return nil
+}
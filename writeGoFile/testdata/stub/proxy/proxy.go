@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"github.com/godbus/dbus"
+	"pkg.deepin.io/lib/dbusutil"
+)
+
+type Object struct {
+	conn        *dbus.Conn
+	serviceName string
+	path        dbus.ObjectPath
+}
+
+func (o *Object) Init_(conn *dbus.Conn, serviceName string, path dbus.ObjectPath) {
+	o.conn, o.serviceName, o.path = conn, serviceName, path
+}
+func (o *Object) Path_() dbus.ObjectPath { return o.path }
+func (o *Object) ServiceName_() string   { return o.serviceName }
+func (o *Object) Go_(method string, flags dbus.Flags, ch chan *dbus.Call, args ...interface{}) *dbus.Call {
+	return nil
+}
+func (o *Object) GetProperty_(flags dbus.Flags, interfaceName, propName string, value interface{}) error {
+	return nil
+}
+func (o *Object) SetProperty_(flags dbus.Flags, interfaceName, propName string, value interface{}) error {
+	return nil
+}
+func (o *Object) ConnectPropertyChanged_(interfaceName, propName string, cb func(hasValue bool, value interface{})) error {
+	return nil
+}
+func (o *Object) ConnectSignal_(rule string, sigRule *dbusutil.SignalRule, cb dbusutil.SignalHandlerFunc) (dbusutil.SignalHandlerId, error) {
+	return 0, nil
+}
+
+type Implementer interface {
+	GetObject_() *Object
+	GetInterfaceName_() string
+}
@@ -0,0 +1,232 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// number of context lines around changed lines in unified diff
+const diffContext = 3
+
+// edit of line, kind is ' ', '-' or '+'
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// get unified diff of old and new text, empty if they are equal
+func UnifiedDiff(oldName string, newName string, oldText []byte, newText []byte) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	// line index of old and new text before every op
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	var changes []int
+	for index, op := range ops {
+		oldPos[index+1], newPos[index+1] = oldPos[index], newPos[index]
+		if op.kind != '+' {
+			oldPos[index+1]++
+		}
+		if op.kind != '-' {
+			newPos[index+1]++
+		}
+		if op.kind != ' ' {
+			changes = append(changes, index)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for cIndex := 0; cIndex < len(changes); {
+		// changes which context lines overlap or touch are in the same hunk, same as diff -u,
+		// that is at most 2*diffContext lines are between them
+		last := cIndex
+		for last+1 < len(changes) && changes[last+1]-changes[last]-1 <= 2*diffContext {
+			last++
+		}
+		start := changes[cIndex] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + diffContext + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[end]-oldPos[start]),
+			hunkRange(newPos[start], newPos[end]-newPos[start]))
+		for _, op := range ops[start:end] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		cIndex = last + 1
+	}
+	return buf.String()
+}
+
+// get range of hunk header, start is line index before hunk
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// split text to lines, line break is kept
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// get edits from old lines to new lines by longest common subsequence,
+// common prefix and suffix are skipped first as generated files usually change a little
+func diffLines(oldLines []string, newLines []string) []diffOp {
+	var prefix, suffix []diffOp
+	for len(oldLines) > 0 && len(newLines) > 0 && oldLines[0] == newLines[0] {
+		prefix = append(prefix, diffOp{kind: ' ', line: oldLines[0]})
+		oldLines, newLines = oldLines[1:], newLines[1:]
+	}
+	for len(oldLines) > 0 && len(newLines) > 0 && oldLines[len(oldLines)-1] == newLines[len(newLines)-1] {
+		suffix = append([]diffOp{{kind: ' ', line: oldLines[len(oldLines)-1]}}, suffix...)
+		oldLines, newLines = oldLines[:len(oldLines)-1], newLines[:len(newLines)-1]
+	}
+
+	// lines are compared by id, so comparing is cheap
+	ids := make(map[string]int)
+	getIds := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for index, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[index] = id
+		}
+		return result
+	}
+	d := &lineDiff{
+		oldLines: oldLines,
+		newLines: newLines,
+		oldIds:   getIds(oldLines),
+		newIds:   getIds(newLines),
+		ops:      prefix,
+	}
+	d.diff(0, len(oldLines), 0, len(newLines))
+	sortChanges(d.ops[len(prefix):])
+	return append(d.ops, suffix...)
+}
+
+// edits of lines found by Hirschberg's algorithm, it needs space linear to lines
+type lineDiff struct {
+	oldLines []string
+	newLines []string
+	oldIds   []int
+	newIds   []int
+	ops      []diffOp
+}
+
+// add edits from old lines [oldLo, oldHi) to new lines [newLo, newHi), old lines are split in
+// half, new lines are split where longest common subsequences of both halves are the longest
+func (d *lineDiff) diff(oldLo int, oldHi int, newLo int, newHi int) {
+	switch {
+	case oldLo == oldHi:
+		d.add('+', d.newLines[newLo:newHi])
+		return
+	case newLo == newHi:
+		d.add('-', d.oldLines[oldLo:oldHi])
+		return
+	case oldHi-oldLo == 1:
+		for j := newLo; j < newHi; j++ {
+			if d.newIds[j] == d.oldIds[oldLo] {
+				d.add('+', d.newLines[newLo:j])
+				d.add(' ', d.oldLines[oldLo:oldHi])
+				d.add('+', d.newLines[j+1:newHi])
+				return
+			}
+		}
+		d.add('-', d.oldLines[oldLo:oldHi])
+		d.add('+', d.newLines[newLo:newHi])
+		return
+	}
+
+	mid := (oldLo + oldHi) / 2
+	forward := lcsLengths(d.oldIds[oldLo:mid], d.newIds[newLo:newHi], false)
+	backward := lcsLengths(d.oldIds[mid:oldHi], d.newIds[newLo:newHi], true)
+	split, best := 0, -1
+	for k := 0; k <= newHi-newLo; k++ {
+		if length := forward[k] + backward[newHi-newLo-k]; length > best {
+			split, best = k, length
+		}
+	}
+	d.diff(oldLo, mid, newLo, newLo+split)
+	d.diff(mid, oldHi, newLo+split, newHi)
+}
+
+func (d *lineDiff) add(kind byte, lines []string) {
+	for _, line := range lines {
+		d.ops = append(d.ops, diffOp{kind: kind, line: line})
+	}
+}
+
+// get lengths of longest common subsequences of a and every prefix of b, or of a and every
+// suffix of b if reverse is true, length for prefix or suffix of k lines is at index k
+func lcsLengths(a []int, b []int, reverse bool) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		ai := i
+		if reverse {
+			ai = len(a) - 1 - i
+		}
+		for j := range b {
+			bj := j
+			if reverse {
+				bj = len(b) - 1 - j
+			}
+			if a[ai] == b[bj] {
+				cur[j+1] = prev[j] + 1
+			} else if prev[j+1] >= cur[j] {
+				cur[j+1] = prev[j+1]
+			} else {
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// removed lines are put before added lines in every run of changes, same as diff does
+func sortChanges(ops []diffOp) {
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].kind != ' ' {
+			end++
+		}
+		sort.SliceStable(ops[start:end], func(i, j int) bool {
+			return ops[start+i].kind == '-' && ops[start+j].kind == '+'
+		})
+		start = end
+	}
+}
@@ -0,0 +1,78 @@
+package writeGoFile
+
+import (
+	"fmt"
+	"strings"
+
+	C "gopkg.in/check.v1"
+)
+
+type udiffSuite struct{}
+
+var _ = C.Suite(&udiffSuite{})
+
+func (*udiffSuite) TestUnifiedDiff(c *C.C) {
+	old := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n")
+	c.Check(UnifiedDiff("a.go", "a.go", old, old), C.Equals, "")
+
+	c.Check(UnifiedDiff("a.go", "a.go", old, []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl")), C.Equals, `--- a.go
++++ a.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+\ No newline at end of file
+`)
+
+	c.Check(UnifiedDiff("/dev/null", "a.go", nil, []byte("a\n")), C.Equals, "--- /dev/null\n+++ a.go\n@@ -0,0 +1 @@\n+a\n")
+}
+
+func (*udiffSuite) TestUnifiedDiffHunks(c *C.C) {
+	// same as diff -u, hunks with at most 2*diffContext lines between them are merged
+	hunks := func(gap int) []string {
+		var old, new []string
+		for i := 1; i <= 20; i++ {
+			old = append(old, fmt.Sprint(i))
+			switch i {
+			case 3:
+				new = append(new, "x")
+			case 3 + gap + 1:
+				new = append(new, "y")
+			default:
+				new = append(new, fmt.Sprint(i))
+			}
+		}
+		diff := UnifiedDiff("a.go", "a.go", []byte(strings.Join(old, "\n")+"\n"),
+			[]byte(strings.Join(new, "\n")+"\n"))
+		var result []string
+		for _, line := range strings.Split(diff, "\n") {
+			if strings.HasPrefix(line, "@@") {
+				result = append(result, line)
+			}
+		}
+		return result
+	}
+	c.Check(hunks(2*diffContext), C.DeepEquals, []string{"@@ -1,13 +1,13 @@"})
+	c.Check(hunks(2*diffContext+1), C.DeepEquals, []string{"@@ -1,6 +1,6 @@", "@@ -8,7 +8,7 @@"})
+
+	c.Check(UnifiedDiff("a.go", "a.go", []byte("1\n2\n3\n4\n5\n6\n"), []byte("1\n2\nx\ny\n5\n6\n")), C.Equals, `--- a.go
++++ a.go
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
+-4
++x
++y
+ 5
+ 6
+`)
+}
@@ -2,38 +2,71 @@ package writeGoFile
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
-	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 )
 
 // check if var is property
-func IsProperty(object *types.Var) bool {
+func IsProperty(object *types.Var, tag string) bool {
+	return CheckProperty(object, tag) == nil
+}
+
+// check if field is exported as property by dbusutil, reason is returned if not
+func CheckProperty(object *types.Var, tag string) error {
 	if object == nil {
-		return false
+		return errors.New("field is nil")
+	}
+	// embedded struct is not property
+	if object.Embedded() {
+		return errors.New("field is embedded")
 	}
+
 	// check if is title
 	name := object.Name()
-	if unicode.IsLower(rune(name[0])) {
-		return false
+	if !object.Exported() {
+		return errors.New("field is not exported")
 	}
 
 	// check if type is signal
 	if name == "signals" || name == "methods" {
-		return false
+		return errors.New("field is declaration of " + name)
 	}
 
-	// check if type is valid
-	var buf bytes.Buffer
-	types.WriteType(&buf, object.Type(), nil)
-	if buf.String() == "invalid type" {
+	// check if property is opted out
+	if reflect.StructTag(tag).Get("prop") == "-" {
+		return errors.New("field is opted out by prop tag")
+	}
+
+	// mutex and other sync types protect properties, they are not properties
+	if isSyncType(object.Type()) {
+		return fmt.Errorf("field type %s is sync primitive", object.Type().String())
+	}
+
+	// check if type is valid and can be marshaled
+	if _, err := SignatureOf(object.Type()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// check if type is declared in sync package, pointer is dereferenced
+func isSyncType(ty types.Type) bool {
+	if pointer, ok := ty.(*types.Pointer); ok {
+		ty = pointer.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
 		return false
 	}
-	return true
+	return named.Obj().Pkg().Path() == "sync"
 }
 
 func IsSignals(object *types.Var) bool {
@@ -51,6 +84,14 @@ func IsSignals(object *types.Var) bool {
 	return false
 }
 
+func IsMethodsDecl(object *types.Var) bool {
+	if object == nil {
+		return false
+	}
+	// check if type is methods declare
+	return object.Name() == "methods"
+}
+
 func IsMethod(object *types.Func) bool {
 	if object == nil {
 		return false
@@ -67,7 +108,17 @@ func IsMethod(object *types.Func) bool {
 		return false
 	}
 
-	return true
+	// dbusutil only exports method whose last result is *dbus.Error
+	signature, ok := object.Type().(*types.Signature)
+	if !ok || signature.Results().Len() == 0 {
+		return false
+	}
+	last := signature.Results().At(signature.Results().Len() - 1)
+	// godbus can not be resolved, can not tell error type
+	if isInvalidType(last) {
+		return true
+	}
+	return isGodbusType(last.Type(), "Error")
 }
 
 func IsInterface(object *types.Func) bool {
@@ -89,26 +140,52 @@ func IsInterface(object *types.Func) bool {
 	return false
 }
 
-func writeNewObject(sb *SourceBody, object *DBusObject) {
-	sb.Pn("func New%s(conn *dbus.Conn) %s {", object.TypeName,
-	)
+func writeNewObject(sb *SourceBody, proxy *ProxyObject) {
+	// path is built at runtime, caller should pass it
+	if proxy.IsPathTemplate() {
+		if proxy.busPath != "" {
+			sb.Pn("// path: %s", proxy.busPath)
+		}
+		sb.Pn("func New%s(conn *dbus.Conn, path dbus.ObjectPath) (*%s, error) {", proxy.TypeName, proxy.TypeName)
+		sb.Pn("if !path.IsValid() {")
+		sb.Pn("return nil, errors.New(\"path is invalid\")")
+		sb.Pn("}")
+		sb.Pn("obj := new(%s)", proxy.TypeName)
+		sb.Pn("obj.Object.Init_(conn, %q, path)", proxy.serviceName)
+		sb.Pn("return obj, nil")
+		sb.Pn("}\n")
+		return
+	}
+	sb.Pn("func New%s(conn *dbus.Conn) *%s {", proxy.TypeName, proxy.TypeName)
 
-	sb.Pn("obj := new(%s)", object.TypeName)
+	sb.Pn("obj := new(%s)", proxy.TypeName)
 
-	sb.Pn("obj.Object.Init_(conn, %s, %s)", object.interfaceName, object.busPath)
+	sb.Pn("obj.Object.Init_(conn, %q, %q)", proxy.serviceName, proxy.busPath)
 
-	sb.Pn("return %obj")
+	sb.Pn("return obj")
 	sb.Pn("}\n")
 }
 
-func writeStruct(sb *SourceBody, object *DBusObject) {
-	log.Println("Object", object.TypeName)
-	sb.Pn("type %s struct {", object.TypeName)
-	sb.Pn("%s // interface %s", object.ObjectName, object.interfaceName)
+// proxy struct embeds struct of every interface, they are empty so proxy.Object can be
+// got from pointer of any of them
+func writeStruct(sb *SourceBody, proxy *ProxyObject) {
+	sb.Pn("type %s struct {", proxy.TypeName)
+	for _, object := range proxy.interfaces {
+		sb.Pn("%s // interface %s", object.ObjectName, object.interfaceName)
+	}
 	sb.Pn("proxy.Object")
 	sb.Pn("}\n")
 }
 
+// accessors of interfaces, members with the same name in different interfaces can be used by them
+func writeInterfaceAccessors(sb *SourceBody, proxy *ProxyObject) {
+	for _, object := range proxy.interfaces {
+		sb.Pn("func (obj *%s) %s() *%s {", proxy.TypeName, upperFirst(object.ObjectName), object.ObjectName)
+		sb.Pn("    return &obj.%s", object.ObjectName)
+		sb.Pn("}\n")
+	}
+}
+
 func writeImplementerMethods(sb *SourceBody, object *DBusObject) {
 	sb.Pn("type %s struct{}", object.ObjectName)
 
@@ -121,124 +198,187 @@ func writeImplementerMethods(sb *SourceBody, object *DBusObject) {
 	sb.Pn("}\n")
 }
 
-func writeMethod(sb *SourceBody, ObjectName string, method *types.Func) {
-	// sb.Pn("// method %s\n", method.Name())
-	methodName := strings.Title(method.Name())
+// names used by generated methods, args can not use them
+var methodReservedNames = map[string]bool{
+	"v":     true,
+	"flags": true,
+	"ch":    true,
+	"call":  true,
+	"err":   true,
+}
 
-	// check if method name is GetInterface
-	if method.Name() == "GetInterfaceName" {
-		return
+// names used by generated Connect<Signal>, signal args can not use them
+var signalReservedNames = map[string]bool{
+	"v":           true,
+	"cb":          true,
+	"obj":         true,
+	"rule":        true,
+	"sigRule":     true,
+	"handlerFunc": true,
+	"sig":         true,
+	"err":         true,
+}
+
+// arg of generated func
+type proxyArg struct {
+	name   string
+	goType string
+}
+
+// get args of generated func, names which are keywords, reserved or used already
+// are replaced by <prefix>_N
+func getProxyArgs(busArgs []*DBusArg, prefix string, used map[string]bool,
+	reserved map[string]bool) ([]proxyArg, error) {
+	var args []proxyArg
+	for index, busArg := range busArgs {
+		goType, err := GoTypeOf(busArg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", busArg.Name, err)
+		}
+		name := busArg.Name
+		if !token.IsIdentifier(name) || name == "_" || reserved[name] || used[name] {
+			name = uniqueName(fmt.Sprintf("%s_%d", prefix, index), used)
+		}
+		used[name] = true
+		args = append(args, proxyArg{name: name, goType: goType})
 	}
+	return args, nil
+}
 
-	// convert to signature
-	signature, ok := method.Type().(*types.Signature)
-	if !ok {
-		log.Print("convert to signature failed")
+func writeMethod(sb *SourceBody, object *DBusObject, method *DBusMethod) {
+	methodName := strings.Title(method.Name)
+
+	// in and out args share names as both are used by method
+	used := make(map[string]bool)
+	inArgs, err := getProxyArgs(method.In, "arg", used, methodReservedNames)
+	if err != nil {
+		object.report(method.Position, SeverityWarning, CodeUnsupportedType,
+			"skip method %s, reason: %v", method.Name, err)
 		return
 	}
-	// get params
-	params := filterTuple(signature.Params())
-	paramsComma := ", "
-	if len(params) == 0 {
-		paramsComma = ""
+	outArgs, err := getProxyArgs(method.Out, "ret", used, methodReservedNames)
+	if err != nil {
+		object.report(method.Position, SeverityWarning, CodeUnsupportedType,
+			"skip method %s, reason: %v", method.Name, err)
+		return
 	}
+
+	sb.Pn("// method %s\n", method.Name)
 	// GoXXX
 	sb.Pn("func (v *%s) Go%s(flags dbus.Flags, ch chan *dbus.Call%s) *dbus.Call {",
-		ObjectName, methodName, paramsComma+getArgsProto(params))
-	sb.Pn("    return v.GetObject_().Go_(v.GetInterfaceName_()+\".%s\", flags, ch %s)",
-		methodName, getArgsName(params))
+		object.ObjectName, methodName, withComma(getArgsProto(inArgs)))
+	sb.Pn("    return v.GetObject_().Go_(v.GetInterfaceName_()+\".%s\", flags, ch%s)",
+		method.Name, withComma(getArgsName(inArgs, false)))
 	sb.Pn("}\n")
 
 	// get results
-	results := filterTuple(signature.Results())
-	if len(results) > 0 {
-		sb.Pn("func (*%s) Store%s(call *dbus.Call) (%s,err error) {", ObjectName,
-			methodName, getArgsProto(results))
-		sb.Pn("    err = call.Store(%s)", getArgsName(results))
+	if len(outArgs) > 0 {
+		sb.Pn("func (*%s) Store%s(call *dbus.Call) (%s, err error) {", object.ObjectName,
+			methodName, getArgsProto(outArgs))
+		sb.Pn("    err = call.Store(%s)", getArgsName(outArgs, true))
 		sb.Pn("    return")
 		sb.Pn("}\n")
-		sb.Pn("func (v *%s) %s(flags dbus.Flags %s) (%s, err error) {",
-			ObjectName, methodName, getArgsProto(params),
-			getArgsProto(results))
-		sb.Pn("    return v.Store%s(", method.Name())
-		sb.Pn("<-v.Go%s(flags, make(chan *dbus.Call, 1)%s%s).Done)",
-			methodName, paramsComma, getArgsName(params))
+		sb.Pn("func (v *%s) %s(flags dbus.Flags%s) (%s, err error) {",
+			object.ObjectName, methodName, withComma(getArgsProto(inArgs)),
+			getArgsProto(outArgs))
+		sb.Pn("    return v.Store%s(", methodName)
+		sb.Pn("<-v.Go%s(flags, make(chan *dbus.Call, 1)%s).Done)",
+			methodName, withComma(getArgsName(inArgs, false)))
 		sb.Pn("}\n")
 	} else {
-		sb.Pn("func (v *%s) %s(flags dbus.Flags%s%s) error {",
-			ObjectName, methodName, paramsComma, getArgsProto(params))
-		sb.Pn("    return (<-v.Go%s(flags, make(chan *dbus.Call, 1)%s%s).Done).Err",
-			methodName, paramsComma, getArgsName(params))
+		sb.Pn("func (v *%s) %s(flags dbus.Flags%s) error {",
+			object.ObjectName, methodName, withComma(getArgsProto(inArgs)))
+		sb.Pn("    return (<-v.Go%s(flags, make(chan *dbus.Call, 1)%s).Done).Err",
+			methodName, withComma(getArgsName(inArgs, false)))
 		sb.Pn("}\n")
 	}
 }
 
-func writeProperty(sb *SourceBody, ObjectName string, prop *types.Var) {
-	sb.Pn("// property %s %s\n", prop.Name(), prop.Type().String())
+func writeProperty(sb *SourceBody, object *DBusObject, prop *DBusProperty) {
+	goType, err := GoTypeOf(prop.Type)
+	if err != nil {
+		object.report(prop.Position, SeverityWarning, CodeUnsupportedType,
+			"skip property %s, reason: %v", prop.Name, err)
+		return
+	}
+	sb.Pn("// property %s %s, access %s\n", prop.Name, prop.Type, prop.Access)
 
-	propType := getPropType(prop)
+	propType := getPropType(prop.Type)
 	if propType != "" {
-		sb.Pn("func (v *%s) %s() %s {", ObjectName, prop.Name(), propType)
+		sb.Pn("func (v *%s) %s() %s {", object.ObjectName, prop.Name, propType)
 		sb.Pn("    return %s{", propType)
 		sb.Pn("        Impl: v,")
-		sb.Pn("        Name: %q,", prop.Name())
+		sb.Pn("        Name: %q,", prop.Name)
 		sb.Pn("    }")
 		sb.Pn("}\n")
 	} else {
-		sb.Pn("func (v *%s) %s() %s {", ObjectName, prop.Name(), prop.Type().String())
-		sb.Pn("    return %s{", prop.Type().String())
+		propType = getComplexPropType(object, prop)
+		sb.Pn("func (v *%s) %s() %s {", object.ObjectName, prop.Name, propType)
+		sb.Pn("    return %s{", propType)
 		sb.Pn("        Impl: v,")
+		sb.Pn("        Name: %q,", prop.Name)
 		sb.Pn("    }")
 		sb.Pn("}\n")
 
-		sb.Pn("type %s struct {", prop.Type().String())
+		sb.Pn("type %s struct {", propType)
 		sb.Pn("Impl proxy.Implementer")
+		sb.Pn("Name string")
 		sb.Pn("}\n")
 
-		if strings.Contains(prop.Name(), "read") {
-			writePropGet(sb, prop, "p.Name")
+		// only generate accessors which access of property allows
+		if prop.CanRead() {
+			writePropGet(sb, propType, goType, "p.Name")
+		}
+		if prop.CanWrite() {
+			writePropSet(sb, propType, goType, "p.Name")
 		}
-		if strings.Contains(prop.Name(), "write") {
-			writePropSet(sb, prop, "p.Name")
+		// property without changed signal can not be watched
+		if prop.CanRead() && prop.EmitsChanged() {
+			writePropConnectChanged(sb, propType, goType, "p.Name")
 		}
-		writePropConnectChanged(sb, prop, "p.Name")
 	}
 }
 
-func writePropGet(sb *SourceBody, prop *types.Var, propName string) {
+// get type of property which has no proxy.PropXxx type, type is declared in the package,
+// interface name keeps it unique
+func getComplexPropType(object *DBusObject, prop *DBusProperty) string {
+	return "Prop" + upperFirst(object.ObjectName) + prop.Name
+}
+
+func writePropGet(sb *SourceBody, propType string, goType string, propName string) {
 	sb.Pn("func (p %s) Get(flags dbus.Flags) (value %s, err error) {",
-		prop.Name(), prop.Type().String())
+		propType, goType)
 	sb.Pn("err = p.Impl.GetObject_().GetProperty_(flags, p.Impl.GetInterfaceName_(),")
 	sb.Pn("%s, &value)", propName)
 	sb.Pn("    return")
 	sb.Pn("}\n")
 }
 
-func writePropSet(sb *SourceBody, prop *types.Var, propName string) {
+func writePropSet(sb *SourceBody, propType string, goType string, propName string) {
 	sb.Pn("func (p %s) Set(flags dbus.Flags, value %s) error {",
-		prop.Name(), prop.Type().String())
+		propType, goType)
 	sb.Pn("return p.Impl.GetObject_().SetProperty_(flags,"+
 		" p.Impl.GetInterfaceName_(), %s, value)", propName)
 	sb.Pn("}\n")
 }
 
-func writePropConnectChanged(sb *SourceBody, prop *types.Var, propName string) {
+func writePropConnectChanged(sb *SourceBody, propType string, goType string, propName string) {
 	sb.Pn("func (p %s) ConnectChanged(cb func(hasValue bool, value %s)) error {",
-		prop.Name(), prop.Type().String())
+		propType, goType)
 	sb.Pn("if cb == nil {")
 	sb.Pn("    return errors.New(\"nil callback\")")
 	sb.Pn("}")
 	sb.Pn("cb0 := func(hasValue bool, value interface{}) {")
+	sb.Pn("var v %s", goType)
 
 	sb.Pn("if hasValue {")
-	sb.Pn("    var v %s", prop.Type().String())
 	sb.Pn("    err := dbus.Store([]interface{}{value}, &v)")
 	sb.Pn("    if err != nil {")
 	sb.Pn("        return")
 	sb.Pn("    }")
 	sb.Pn("    cb(true, v)")
 	sb.Pn("} else {")
-	sb.Pn("    cb(false, %s)", "nil")
+	sb.Pn("    cb(false, v)")
 	sb.Pn("}")
 
 	sb.Pn("}") // end cb0
@@ -248,49 +388,38 @@ func writePropConnectChanged(sb *SourceBody, prop *types.Var, propName string) {
 	sb.Pn("}\n")
 }
 
-func writeSignal(sb *SourceBody, ObjectName string, signal *types.Var) {
-	sb.Pn("// signal %s\n", signal.Name())
-	methodName := strings.Title(signal.Name())
-	log.Print(methodName)
+func writeSignal(sb *SourceBody, object *DBusObject, signal *DBusSignal) {
+	methodName := strings.Title(signal.Name)
 
-	obj, ok := signal.Type().(*types.Struct)
-	if !ok {
+	elms, err := getProxyArgs(signal.Args, "arg", make(map[string]bool), signalReservedNames)
+	if err != nil {
+		object.report(signal.Position, SeverityWarning, CodeUnsupportedType,
+			"skip signal %s, reason: %v", signal.Name, err)
 		return
 	}
-	var elms []*types.Var
-	for oIndex := 0; oIndex < obj.NumFields(); oIndex++ {
-		pVar := obj.Field(oIndex)
-		if isInvalidType(pVar) {
-			continue
-		}
-		elms = append(elms, pVar)
-	}
-	log.Print(elms)
+	sb.Pn("// signal %s\n", signal.Name)
 	sb.Pn("func (v *%s) Connect%s(cb func(%s)) (dbusutil.SignalHandlerId, error) {",
-		ObjectName, methodName, getArgsProto(elms))
+		object.ObjectName, methodName, getArgsProto(elms))
 	sb.Pn("if cb == nil {")
 	sb.Pn("   return 0, errors.New(\"nil callback\")")
 	sb.Pn("}")
 	sb.Pn("obj := v.GetObject_()")
 	sb.Pn("rule := fmt.Sprintf(")
 	sb.writeStr(`"type='signal',interface='%s',member='%s',path='%s',sender='%s'",` + "\n")
-	sb.Pn("v.GetInterfaceName_(), %q, obj.Path_(), obj.ServiceName_())\n", signal.Name())
+	sb.Pn("v.GetInterfaceName_(), %q, obj.Path_(), obj.ServiceName_())\n", signal.Name)
 	sb.Pn("sigRule := &dbusutil.SignalRule{")
 	sb.Pn("Path: obj.Path_(),")
-	sb.Pn("Name: v.GetInterfaceName_() + \".%s\",", signal.Name())
+	sb.Pn("Name: v.GetInterfaceName_() + \".%s\",", signal.Name)
 	sb.Pn("}")
 	sb.Pn("handlerFunc := func(sig *dbus.Signal) {")
 
 	if len(elms) > 0 {
 		for _, arg := range elms {
-			var buf bytes.Buffer
-			types.WriteType(&buf, arg.Type(), nil)
-			argType := buf.String()
-			sb.Pn("var %s %s", arg.Name(), argType)
+			sb.Pn("var %s %s", arg.name, arg.goType)
 		}
-		sb.Pn("err := dbus.Store(sig.Body %s)", getArgsName(elms))
+		sb.Pn("err := dbus.Store(sig.Body, %s)", getArgsName(elms, true))
 		sb.Pn("if err == nil {")
-		sb.Pn("    cb(%s)", getArgsName(elms))
+		sb.Pn("    cb(%s)", getArgsName(elms, false))
 		sb.Pn("}")
 	} else {
 		sb.Pn("cb()")
@@ -309,103 +438,81 @@ func isInvalidType(elem *types.Var) bool {
 	return false
 }
 
-var propBaseTypeMap = []string{
-	"byte",
-	"bool",
-	"int16",
-	"uint16",
-	"int32",
-	"uint32",
-	"int64",
-	"uint64",
-	"double",
-	"string",
-	"objectPath",
+// proxy property type of D-Bus signature
+var propBaseTypeMap = map[string]string{
+	"y": "Byte",
+	"b": "Bool",
+	"n": "Int16",
+	"q": "Uint16",
+	"i": "Int32",
+	"u": "Uint32",
+	"x": "Int64",
+	"t": "Uint64",
+	"d": "Double",
+	"s": "String",
+	"o": "ObjectPath",
 }
 
-func getPropType(ty *types.Var) string {
-	// if is bcType type
-	if bcType, ok := ty.Type().(*types.Basic); ok {
-		if IsExitItem(bcType.Name(), propBaseTypeMap) {
-			return "proxy.Prop" + strings.Title(bcType.Name())
-		}
+func getPropType(sig string) string {
+	// if is base type
+	if name, ok := propBaseTypeMap[sig]; ok {
+		return "proxy.Prop" + name
 	}
-	// if is slice
-	if scType, ok := ty.Type().(*types.Slice); ok {
-		if IsExitItem(scType.Elem().String(), propBaseTypeMap) {
-			return "proxy.Prop" + strings.Title(scType.Elem().String()) + "Array"
+	// if is array of base type
+	if strings.HasPrefix(sig, "a") {
+		if name, ok := propBaseTypeMap[sig[1:]]; ok {
+			return "proxy.Prop" + name + "Array"
 		}
 	}
 	return ""
 }
 
 // get proto
-func getArgsProto(args []*types.Var) string {
-	if len(args) == 0 {
-		return ""
+func getArgsProto(args []proxyArg) string {
+	var protos []string
+	for _, arg := range args {
+		protos = append(protos, arg.name+" "+arg.goType)
 	}
-	var bufProto bytes.Buffer
-	for pIndex := 0; pIndex < len(args); pIndex++ {
-		argName := fmt.Sprintf("arg_%d", pIndex)
-		pVar := args[pIndex]
-		if pVar.Name() != "" {
-			argName = pVar.Name()
-		}
-		if isInvalidType(pVar) {
-			continue
-		}
-
-		// get types value
-		var buf bytes.Buffer
-		types.WriteType(&buf, pVar.Type(), nil)
+	return strings.Join(protos, ", ")
+}
 
-		// add to proto
-		bufProto.WriteString(argName + " " + buf.String() + ",")
+// get names of args, address of args is used to store values
+func getArgsName(args []proxyArg, addr bool) string {
+	var names []string
+	for _, arg := range args {
+		if addr {
+			names = append(names, "&"+arg.name)
+		} else {
+			names = append(names, arg.name)
+		}
 	}
-	return strings.TrimRight(bufProto.String(), ",")
+	return strings.Join(names, ", ")
 }
 
-// get proto
-func getArgsName(args []*types.Var) string {
-	if len(args) == 0 {
+// add leading comma to list which follows other args
+func withComma(list string) string {
+	if list == "" {
 		return ""
 	}
-	var bufProto bytes.Buffer
-	for aIndex := 0; aIndex < len(args); aIndex++ {
-		argName := fmt.Sprintf("arg_%d", aIndex)
-		pVar := args[aIndex]
-		if pVar.Name() != "" {
-			argName = pVar.Name()
-		}
-		if isInvalidType(pVar) {
-			continue
-		}
-
-		// get types value
-		var buf bytes.Buffer
-		types.WriteType(&buf, pVar.Type(), nil)
-
-		// add to proto
-		bufProto.WriteString(argName + ",")
-	}
-	return strings.TrimRight(bufProto.String(), ",")
+	return ", " + list
 }
 
-// filter tuple
-func filterTuple(tuple *types.Tuple) []*types.Var {
-	var elms []*types.Var
-	if tuple.Len() == 0 {
-		return nil
-	}
-	// filter invalid type
-	for tIndex := 0; tIndex < tuple.Len(); tIndex++ {
-		elem := tuple.At(tIndex)
-		if isInvalidType(elem) {
+// get in and out args of method as seen on bus, args injected by godbus are removed
+// from in args, and the trailing *dbus.Error is removed from out args
+func getMethodArgs(signature *types.Signature) (in []*types.Var, out []*types.Var) {
+	params := signature.Params()
+	for pIndex := 0; pIndex < params.Len(); pIndex++ {
+		param := params.At(pIndex)
+		if isGodbusType(param.Type(), "Sender") || isGodbusType(param.Type(), "Message") {
 			continue
 		}
-		elms = append(elms, elem)
+		in = append(in, param)
+	}
+	results := signature.Results()
+	for rIndex := 0; rIndex < results.Len()-1; rIndex++ {
+		out = append(out, results.At(rIndex))
 	}
-	return elms
+	return
 }
 
 func IsExitItem(source interface{}, array interface{}) bool {
@@ -421,71 +528,94 @@ func IsExitItem(source interface{}, array interface{}) bool {
 	return false
 }
 
-func GetDBusPathName(file *ast.File) []*DBusElem {
-	if file == nil {
-		return nil
-	}
-	for _, astDecl := range file.Decls {
-		if funcDecl, ok := astDecl.(*ast.FuncDecl); ok {
-			bodyList := funcDecl.Body.List
-			var callExpr *ast.CallExpr
-			for _, stmt := range bodyList {
-				if expr, ok := stmt.(*ast.ExprStmt); ok {
-					X := expr.X
-					if X == nil {
-						continue
-					}
-					if callExpr, ok = X.(*ast.CallExpr); !ok {
-						continue
-					}
-				} else if assign, ok := stmt.(*ast.AssignStmt); ok {
-					for _, expr := range assign.Rhs {
-						if callExpr, ok = expr.(*ast.CallExpr); !ok {
-							continue
-						}
-					}
-				}
-				if callExpr == nil {
-					continue
-				}
-				Func := callExpr.Fun
-				if Func == nil {
-					continue
-				}
-				if selector, ok := Func.(*ast.SelectorExpr); ok {
-					if selector.Sel.Name == "Export" {
-						argsLen := len(callExpr.Args)
-						if argsLen < 2 {
-							return nil
-						}
-						busPath, busConst := GetDBusPathFromExpr(callExpr.Args[0])
-						if busPath == "" && busConst == "" {
-							return nil
-						}
-						var els []*DBusElem
-						for index := 1; index < argsLen; index++ {
-							busObj, info := GetObjectNameFromExpr(callExpr.Args[index])
-							if busObj == "" && info == nil {
-								continue
-							}
-							elem := &DBusElem{
-								DBusPath:    busPath,
-								DBusObjName: busObj,
-								DBusConst:   busConst,
-								DBusInfo:    info,
-							}
-							els = append(els, elem)
-						}
-						if len(els) == 0 {
-							return nil
-						}
-						return els
-					}
-				}
+// check if selector calls Export of dbusutil.Service, call is kept if type of its receiver
+// is unknown, e.g. package is checked with stub importer
+func isServiceExport(info *types.Info, selector *ast.SelectorExpr) bool {
+	if selector.Sel.Name != "Export" {
+		return false
+	}
+	if info == nil {
+		return true
+	}
+	fn, ok := info.Uses[selector.Sel].(*types.Func)
+	if !ok {
+		// function of package is not method, even if package is unknown
+		if ident, ok := selector.X.(*ast.Ident); ok {
+			if _, ok := info.Uses[ident].(*types.PkgName); ok {
+				return false
 			}
 		}
+		return info.Uses[selector.Sel] == nil
 	}
-	return nil
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && IsServiceType(recv.Type())
+}
+
+// UnresolvedExport is implementer of export call which can not be resolved from syntax,
+// it may still be resolved by call graph analysis
+type UnresolvedExport struct {
+	// position of export call
+	Call token.Position
+	// range of implementer
+	Position *Position
+}
+
+// report implementer can not be resolved
+func (u *UnresolvedExport) Report() {
+	Reportf(u.Position, SeverityWarning, CodeUnresolvedExport, "can not resolve implementer of export call")
+}
+
+// get every export call of dbusutil.Service in file, include those in nested blocks, closures
+// and goroutines, implementers which can not be resolved from syntax are returned apart
+func GetDBusPathName(fSet *token.FileSet, file *ast.File, info *types.Info) ([]*DBusElem, []*UnresolvedExport) {
+	if file == nil {
+		return nil, nil
+	}
+	var els []*DBusElem
+	var unresolved []*UnresolvedExport
+	ast.Inspect(file, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok || !isServiceExport(info, selector) {
+			return true
+		}
+		position := fSet.Position(callExpr.Pos())
+		end := fSet.Position(callExpr.End())
+		argsLen := len(callExpr.Args)
+		if argsLen < 2 {
+			Reportf(RangeOf(position, end), SeverityWarning, CodeUnresolvedExport, "export call has no implementer")
+			return true
+		}
+		// path which is not literal or const is evaluated later
+		busPath, busConst := GetDBusPathFromExpr(callExpr.Args[0])
+		for index := 1; index < argsLen; index++ {
+			busObj, info := GetObjectNameFromExpr(callExpr.Args[index])
+			if busObj == "" && info == nil {
+				arg := callExpr.Args[index]
+				unresolved = append(unresolved, &UnresolvedExport{
+					Call:     position,
+					Position: NewRange(fSet, arg.Pos(), arg.End()),
+				})
+				continue
+			}
+			elem := &DBusElem{
+				DBusPath:     busPath,
+				DBusObjName:  busObj,
+				DBusConst:    busConst,
+				DBusInfo:     info,
+				DBusPathExpr: callExpr.Args[0],
+				ExportRecv:   selector.X,
+				Position:     position,
+				End:          end,
+			}
+			els = append(els, elem)
+		}
+		return true
+	})
+	return els, unresolved
 }
 
 func GetDBusObjNameFromObj(object *ast.Object, info *StructInfo) string {
@@ -523,6 +653,30 @@ func GetDBusObjNameFromObj(object *ast.Object, info *StructInfo) string {
 	return ""
 }
 
+// get constant string value of expression, constants from other files and packages are resolved by type checker
+func GetConstString(info *types.Info, expr ast.Expr) string {
+	if info == nil || expr == nil {
+		return ""
+	}
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return ""
+	}
+	return constant.StringVal(tv.Value)
+}
+
+// get unquoted value of string literal
+func unquoteLit(bc *ast.BasicLit) string {
+	if bc.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(bc.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
 func GetDBusPathFromObj(obj *ast.Object) string {
 	valueSpec, ok := obj.Decl.(*ast.ValueSpec)
 	if !ok {
@@ -536,10 +690,14 @@ func GetDBusPathFromObj(obj *ast.Object) string {
 	if !ok {
 		return ""
 	}
-	return bc.Value
+	return unquoteLit(bc)
 }
 
 func GetDBusPathFromExpr(expr ast.Expr) (string, string) {
+	// literal path
+	if bc, ok := expr.(*ast.BasicLit); ok {
+		return unquoteLit(bc), ""
+	}
 	astIdent, ok := expr.(*ast.Ident)
 	if !ok {
 		return "", ""
@@ -559,12 +717,19 @@ func GetObjectNameFromExpr(expr ast.Expr) (string, *StructInfo) {
 		if X == nil {
 			return "", nil
 		}
+		// &Manager{}
+		if lit, ok := X.(*ast.CompositeLit); ok {
+			if litIdent, ok := lit.Type.(*ast.Ident); ok {
+				return litIdent.Name, nil
+			}
+			return "", nil
+		}
 		secExpr, ok := X.(*ast.SelectorExpr)
 		if !ok {
 			return "", nil
 		}
 		xIdent, ok := secExpr.X.(*ast.Ident)
-		if !ok {
+		if !ok || xIdent.Obj == nil {
 			return "", nil
 		}
 		astField, ok := xIdent.Obj.Decl.(*ast.Field)
@@ -592,7 +757,7 @@ func GetObjectNameFromExpr(expr ast.Expr) (string, *StructInfo) {
 	secExpr, ok := expr.(*ast.SelectorExpr)
 	if ok {
 		xIdent, ok := secExpr.X.(*ast.Ident)
-		if !ok {
+		if !ok || xIdent.Obj == nil {
 			return "", nil
 		}
 		astField, ok := xIdent.Obj.Decl.(*ast.Field)
@@ -635,6 +800,9 @@ func GetObjectNameFromExpr(expr ast.Expr) (string, *StructInfo) {
 
 func GetObjReturn(assign *ast.AssignStmt) string {
 	rhs := assign.Rhs
+	if len(rhs) == 0 {
+		return ""
+	}
 	rh := rhs[0]
 	callExpr, ok := rh.(*ast.CallExpr)
 	if !ok {
@@ -656,8 +824,11 @@ func GetObjReturn(assign *ast.AssignStmt) string {
 	if !ok {
 		return ""
 	}
+	if funcDecl.Type.Results == nil {
+		return ""
+	}
 	rtList := funcDecl.Type.Results.List
-	if len(rtList) < 0 {
+	if len(rtList) == 0 {
 		return ""
 	}
 	field, ok := rtList[0].Type.(*ast.StarExpr)
@@ -0,0 +1,76 @@
+package writeGoFile
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	C "gopkg.in/check.v1"
+)
+
+type utilsSuite struct{}
+
+var _ = C.Suite(&utilsSuite{})
+
+// object of tests, its go type is declared at line 1 of test.go
+func newTestObject(serviceName string, busPath string, itfName string) *DBusObject {
+	object := NewDBusObject()
+	object.SetServiceName(serviceName)
+	object.SetBusType("system")
+	object.SetDBusPath(busPath)
+	object.SetInterfaceName(itfName)
+	object.source = &ObjectSource{Position: &Position{File: "test.go", Line: 1}}
+	return object
+}
+
+const exportSource = `package exports
+
+import "pkg.deepin.io/lib/dbusutil"
+
+type Manager struct{}
+
+func (*Manager) GetInterfaceName() string { return "com.deepin.Test" }
+
+type cache struct{}
+
+func (cache) Export(key string) {}
+
+func start(service *dbusutil.Service, c cache) {
+	service.Export("/com/deepin/Test", &Manager{})
+	c.Export("manager")
+	var implementer dbusutil.Implementer = &Manager{}
+	service.Export("/com/deepin/Test2", implementer)
+}
+`
+
+// only export of dbusutil.Service is collected, others are not reported as unresolved
+func (*utilsSuite) TestGetDBusPathName(c *C.C) {
+	TakeDiagnostics()
+	fSet := token.NewFileSet()
+	imp := loadStubs(c, fSet)
+	f, err := parser.ParseFile(fSet, "exports.go", exportSource, 0)
+	c.Assert(err, C.IsNil)
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	_, err = (&types.Config{Importer: imp}).Check("exports", fSet, []*ast.File{f}, info)
+	c.Assert(err, C.IsNil)
+
+	elems, unresolved := GetDBusPathName(fSet, f, info)
+	c.Assert(elems, C.HasLen, 1)
+	c.Check(elems[0].DBusPath, C.Equals, "/com/deepin/Test")
+	c.Check(elems[0].DBusObjName, C.Equals, "Manager")
+	// implementer is returned to be reported if call graph can not resolve it either
+	c.Assert(unresolved, C.HasLen, 1)
+	c.Check(unresolved[0].Call, C.Equals, fSet.Position(f.Pos()+token.Pos(strings.Index(exportSource,
+		`service.Export("/com/deepin/Test2"`))))
+	c.Check(unresolved[0].Position, C.DeepEquals, &Position{File: "exports.go", Line: 17, Column: 38,
+		EndLine: 17, EndColumn: 49})
+	c.Check(TakeDiagnostics(), C.HasLen, 0)
+
+	// receiver type is unknown without type info
+	elems, unresolved = GetDBusPathName(fSet, f, nil)
+	c.Check(elems, C.HasLen, 1)
+	c.Check(unresolved, C.HasLen, 1)
+	c.Check(TakeDiagnostics(), C.HasLen, 1)
+}
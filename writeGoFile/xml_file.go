@@ -0,0 +1,381 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// XML document type declaration of the introspection format version 1.0
+const IntrospectDeclarationString = `
+	<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+	 "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+`
+
+// introspect node, same as godbus introspect.Node
+type IntrospectNode struct {
+	XMLName    xml.Name              `xml:"node"`
+	Name       string                `xml:"name,attr,omitempty"`
+	Comment    string                `xml:",comment"`
+	Interfaces []IntrospectInterface `xml:"interface"`
+	Children   []IntrospectNode      `xml:"node,omitempty"`
+}
+
+// introspect interface
+type IntrospectInterface struct {
+	Name        string                 `xml:"name,attr"`
+	Methods     []IntrospectMethod     `xml:"method"`
+	Signals     []IntrospectSignal     `xml:"signal"`
+	Properties  []IntrospectProperty   `xml:"property"`
+	Annotations []IntrospectAnnotation `xml:"annotation"`
+}
+
+// introspect method
+type IntrospectMethod struct {
+	Name        string                 `xml:"name,attr"`
+	Args        []IntrospectArg        `xml:"arg"`
+	Annotations []IntrospectAnnotation `xml:"annotation"`
+}
+
+// introspect signal
+type IntrospectSignal struct {
+	Name        string                 `xml:"name,attr"`
+	Args        []IntrospectArg        `xml:"arg"`
+	Annotations []IntrospectAnnotation `xml:"annotation"`
+}
+
+// introspect property
+type IntrospectProperty struct {
+	Name        string                 `xml:"name,attr"`
+	Type        string                 `xml:"type,attr"`
+	Access      string                 `xml:"access,attr"`
+	Annotations []IntrospectAnnotation `xml:"annotation"`
+}
+
+// introspect arg
+type IntrospectArg struct {
+	Name      string `xml:"name,attr,omitempty"`
+	Type      string `xml:"type,attr"`
+	Direction string `xml:"direction,attr,omitempty"`
+}
+
+// introspect annotation
+type IntrospectAnnotation struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// interfaces implemented by proxy.Object, proxies are not generated for them
+var standardInterfaces = map[string]bool{
+	"org.freedesktop.DBus.Introspectable": true,
+	"org.freedesktop.DBus.Peer":           true,
+	"org.freedesktop.DBus.Properties":     true,
+}
+
+// prefix of comment which keeps path template, see WriteXml
+const pathCommentPrefix = "path:"
+
+// convert bus object to introspect interface
+func (o *DBusObject) IntrospectInterface() IntrospectInterface {
+	itf := IntrospectInterface{
+		Name:        o.interfaceName,
+		Annotations: getIntrospectAnnotations(o.annotations),
+	}
+
+	// add methods
+	for _, method := range o.methods {
+		itf.Methods = append(itf.Methods, IntrospectMethod{
+			Name:        method.Name,
+			Args:        append(getIntrospectArgs(method.In, "in"), getIntrospectArgs(method.Out, "out")...),
+			Annotations: getIntrospectAnnotations(method.Annotations),
+		})
+	}
+
+	// add signals
+	for _, signal := range o.signals {
+		itf.Signals = append(itf.Signals, IntrospectSignal{
+			Name:        signal.Name,
+			Args:        getIntrospectArgs(signal.Args, ""),
+			Annotations: getIntrospectAnnotations(signal.Annotations),
+		})
+	}
+
+	// add properties
+	for _, prop := range o.properties {
+		property := IntrospectProperty{
+			Name:   prop.Name,
+			Type:   prop.Type,
+			Access: prop.Access,
+		}
+		// emit true is default, annotate others only
+		if prop.Emit != EmitTrue {
+			property.Annotations = append(property.Annotations, IntrospectAnnotation{
+				Name:  emitsChangedAnnotation,
+				Value: prop.Emit,
+			})
+		}
+		property.Annotations = append(property.Annotations, getIntrospectAnnotations(prop.Annotations)...)
+		itf.Properties = append(itf.Properties, property)
+	}
+	return itf
+}
+
+// get introspect annotations of model
+func getIntrospectAnnotations(annotations []*DBusAnnotation) []IntrospectAnnotation {
+	var result []IntrospectAnnotation
+	for _, annotation := range annotations {
+		result = append(result, IntrospectAnnotation{Name: annotation.Name, Value: annotation.Value})
+	}
+	return result
+}
+
+// get annotations of model, annotation named skip is dropped as it is kept in other field of model
+func getDBusAnnotations(annotations []IntrospectAnnotation, skip string) []*DBusAnnotation {
+	var result []*DBusAnnotation
+	for _, annotation := range annotations {
+		if annotation.Name == skip {
+			continue
+		}
+		result = append(result, &DBusAnnotation{Name: annotation.Name, Value: annotation.Value})
+	}
+	return result
+}
+
+// get introspect args of direction
+func getIntrospectArgs(busArgs []*DBusArg, direction string) []IntrospectArg {
+	var args []IntrospectArg
+	for _, busArg := range busArgs {
+		args = append(args, IntrospectArg{
+			Name:      busArg.Name,
+			Type:      busArg.Type,
+			Direction: direction,
+		})
+	}
+	return args
+}
+
+// write introspect xml of bus object
+func WriteXml(w io.Writer, object *DBusObject) error {
+	node := IntrospectNode{
+		Interfaces: []IntrospectInterface{object.IntrospectInterface()},
+	}
+	// path template can not be node name, keep it as comment
+	if object.IsPathTemplate() {
+		node.Comment = " " + pathCommentPrefix + " " + object.busPath + " "
+	}
+	return writeIntrospectNode(w, node, InputHash([]*DBusObject{object}))
+}
+
+// write introspect xml of proxy object, all interfaces at its path are included
+func WriteProxyXml(w io.Writer, proxy *ProxyObject) error {
+	var node IntrospectNode
+	for _, object := range proxy.interfaces {
+		node.Interfaces = append(node.Interfaces, object.IntrospectInterface())
+	}
+	if IsPathTemplate(proxy.busPath) {
+		node.Comment = " " + pathCommentPrefix + " " + proxy.busPath + " "
+	}
+	return writeIntrospectNode(w, node, InputHash(proxy.interfaces))
+}
+
+// write introspect node with generated comment and document type declaration
+func writeIntrospectNode(w io.Writer, node IntrospectNode, inputHash string) error {
+	data, err := xml.MarshalIndent(node, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "<!-- %s -->\n<!-- input hash: %s -->\n", GeneratedComment, inputHash)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, strings.TrimLeft(IntrospectDeclarationString, "\n"))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// save introspect xml of bus object to dir, file is named after interface
+func SaveXml(out Output, dir string, object *DBusObject) (string, error) {
+	if object.interfaceName == "" {
+		return "", fmt.Errorf("interface name of object at %q is empty", object.busPath)
+	}
+	filename := filepath.Join(dir, object.interfaceName+".xml")
+	var buf bytes.Buffer
+	err := WriteXml(&buf, object)
+	if err != nil {
+		return "", err
+	}
+	return filename, out.WriteFile(filename, buf.Bytes())
+}
+
+// load bus objects from introspect xml file, see ParseXml
+func LoadXml(filename string, serviceName string, busType string, path string) ([]*DBusObject, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	objects, err := ParseXml(f, serviceName, busType, path)
+	if err != nil {
+		return nil, fmt.Errorf("parse xml %s failed, err: %v", filename, err)
+	}
+	return objects, nil
+}
+
+// parse introspect xml to bus objects, one object for each interface of node and its children.
+// path of root node is its name, or path template kept in comment, or path if both are empty,
+// names of children are relative to path of parent
+func ParseXml(r io.Reader, serviceName string, busType string, path string) ([]*DBusObject, error) {
+	var node IntrospectNode
+	err := xml.NewDecoder(r).Decode(&node)
+	if err != nil {
+		return nil, err
+	}
+	nodePath := node.Name
+	if nodePath == "" {
+		comment := strings.TrimSpace(node.Comment)
+		if strings.HasPrefix(comment, pathCommentPrefix) {
+			nodePath = strings.TrimSpace(strings.TrimPrefix(comment, pathCommentPrefix))
+		}
+	}
+	if nodePath == "" {
+		nodePath = path
+	}
+	var objects []*DBusObject
+	addIntrospectNode(&objects, node, nodePath, serviceName, busType)
+	return objects, nil
+}
+
+// add objects of interfaces of node and its children
+func addIntrospectNode(objects *[]*DBusObject, node IntrospectNode, path string, serviceName string, busType string) {
+	for _, itf := range node.Interfaces {
+		if standardInterfaces[itf.Name] {
+			continue
+		}
+		object := NewDBusObject()
+		object.SetServiceName(serviceName)
+		object.SetBusType(busType)
+		object.SetDBusPath(path)
+		object.SetInterfaceName(itf.Name)
+		object.SetIntrospectInterface(itf)
+		*objects = append(*objects, object)
+	}
+	for _, child := range node.Children {
+		childPath := child.Name
+		if !strings.HasPrefix(childPath, "/") {
+			if path == "" {
+				Reportf(nil, SeverityWarning, CodeUnresolvedPath, "path of parent of node %s is unknown", child.Name)
+				childPath = ""
+			} else {
+				childPath = strings.TrimSuffix(path, "/") + "/" + child.Name
+			}
+		}
+		addIntrospectNode(objects, child, childPath, serviceName, busType)
+	}
+}
+
+// set members of bus object from introspect interface, members which signatures can not be
+// converted to go types are skipped
+func (o *DBusObject) SetIntrospectInterface(itf IntrospectInterface) {
+	// emit behavior of interface is kept as emit of its properties
+	o.annotations = getDBusAnnotations(itf.Annotations, emitsChangedAnnotation)
+	for _, method := range itf.Methods {
+		busMethod := &DBusMethod{
+			Name:        method.Name,
+			Annotations: getDBusAnnotations(method.Annotations, ""),
+		}
+		for _, arg := range method.Args {
+			// direction of method arg is in by default
+			if arg.Direction == "out" {
+				busMethod.Out = append(busMethod.Out, &DBusArg{Name: arg.Name, Type: arg.Type})
+			} else {
+				busMethod.In = append(busMethod.In, &DBusArg{Name: arg.Name, Type: arg.Type})
+			}
+		}
+		if err := checkArgTypes(busMethod.In, busMethod.Out); err != nil {
+			o.report(nil, SeverityWarning, CodeUnsupportedType, "skip method %s, reason: %v", method.Name, err)
+			continue
+		}
+		o.AddMethod(busMethod)
+	}
+
+	for _, signal := range itf.Signals {
+		busSignal := &DBusSignal{
+			Name:        signal.Name,
+			Annotations: getDBusAnnotations(signal.Annotations, ""),
+		}
+		for _, arg := range signal.Args {
+			busSignal.Args = append(busSignal.Args, &DBusArg{Name: arg.Name, Type: arg.Type})
+		}
+		if err := checkArgTypes(busSignal.Args); err != nil {
+			o.report(nil, SeverityWarning, CodeUnsupportedType, "skip signal %s, reason: %v", signal.Name, err)
+			continue
+		}
+		o.AddSignal(busSignal)
+	}
+
+	// emit behavior of interface is default of its properties
+	itfEmit := getAnnotation(itf.Annotations, emitsChangedAnnotation)
+	for _, property := range itf.Properties {
+		if _, err := GoTypeOf(property.Type); err != nil {
+			o.report(nil, SeverityWarning, CodeUnsupportedType, "skip property %s, reason: %v", property.Name, err)
+			continue
+		}
+		prop := &DBusProperty{
+			Name:   property.Name,
+			Type:   property.Type,
+			Access: property.Access,
+			Emit:   EmitTrue,
+			// emit behavior is kept as emit
+			Annotations: getDBusAnnotations(property.Annotations, emitsChangedAnnotation),
+		}
+		if emit := getAnnotation(property.Annotations, emitsChangedAnnotation); emit != "" {
+			prop.Emit = emit
+		} else if itfEmit != "" {
+			prop.Emit = itfEmit
+		}
+		switch prop.Access {
+		case AccessRead, AccessWrite, AccessReadWrite:
+		default:
+			o.report(nil, SeverityWarning, CodeInvalidProperty, "skip property %s, reason: invalid access %q",
+				property.Name, property.Access)
+			continue
+		}
+		switch prop.Emit {
+		case EmitTrue, EmitFalse, EmitInvalidates, EmitConst:
+		default:
+			o.report(nil, SeverityWarning, CodeInvalidProperty, "invalid emit behavior %q of property %s, use %s",
+				prop.Emit, property.Name, EmitTrue)
+			prop.Emit = EmitTrue
+		}
+		o.AddProperty(prop)
+	}
+}
+
+// check if types of args can be converted to go types
+func checkArgTypes(argsList ...[]*DBusArg) error {
+	for _, args := range argsList {
+		for _, arg := range args {
+			if _, err := GoTypeOf(arg.Type); err != nil {
+				return fmt.Errorf("arg %s: %v", arg.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// get value of annotation, empty if not found
+func getAnnotation(annotations []IntrospectAnnotation, name string) string {
+	for _, annotation := range annotations {
+		if annotation.Name == name {
+			return annotation.Value
+		}
+	}
+	return ""
+}
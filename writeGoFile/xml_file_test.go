@@ -0,0 +1,88 @@
+package writeGoFile
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	C "gopkg.in/check.v1"
+)
+
+type xmlSuite struct{}
+
+var _ = C.Suite(&xmlSuite{})
+
+func (*xmlSuite) TestGenerateFromXml(c *C.C) {
+	dir := filepath.Join("testdata", "xml")
+	objects, err := LoadXml(filepath.Join(dir, "login1.xml"), "org.freedesktop.login1", "system", "")
+	c.Assert(err, C.IsNil)
+	// standard interfaces are implemented by proxy.Object
+	c.Assert(objects, C.HasLen, 2)
+	c.Check(objects[1].GetDBusPath(), C.Equals, "/org/freedesktop/login1/seat/seat0")
+
+	formatted, err := format.Source(generateProxy(c, "login1", objects))
+	c.Assert(err, C.IsNil)
+	goldenFile := filepath.Join(dir, "auto.go.golden")
+	if *updateGolden {
+		c.Assert(os.WriteFile(goldenFile, formatted, 0644), C.IsNil)
+	}
+	golden, err := os.ReadFile(goldenFile)
+	c.Assert(err, C.IsNil)
+	c.Check(string(formatted), C.Equals, string(golden))
+
+	fSet := token.NewFileSet()
+	imp := loadStubs(c, fSet)
+	f, err := parser.ParseFile(fSet, "auto.go", formatted, 0)
+	c.Assert(err, C.IsNil)
+	_, err = (&types.Config{Importer: imp}).Check("login1", fSet, []*ast.File{f}, nil)
+	c.Check(err, C.IsNil)
+}
+
+const annotatedXml = `<node name="/com/deepin/Test">
+  <interface name="com.deepin.Test">
+    <annotation name="org.freedesktop.DBus.Deprecated" value="true"/>
+    <annotation name="org.freedesktop.DBus.Property.EmitsChangedSignal" value="invalidates"/>
+    <method name="Ping">
+      <annotation name="org.freedesktop.DBus.Method.NoReply" value="true"/>
+    </method>
+    <signal name="Changed">
+      <arg type="s"/>
+      <annotation name="org.freedesktop.DBus.Deprecated" value="true"/>
+    </signal>
+    <property name="Name" type="s" access="read">
+      <annotation name="org.qtproject.QtDBus.QtTypeName" value="QString"/>
+    </property>
+  </interface>
+</node>`
+
+// annotations are kept in model and written back to xml
+func (*xmlSuite) TestXmlAnnotations(c *C.C) {
+	objects, err := ParseXml(strings.NewReader(annotatedXml), "com.deepin.Test", "session", "")
+	c.Assert(err, C.IsNil)
+	c.Assert(objects, C.HasLen, 1)
+	object := objects[0]
+	c.Check(object.annotations, C.DeepEquals,
+		[]*DBusAnnotation{{Name: "org.freedesktop.DBus.Deprecated", Value: "true"}})
+	c.Check(object.methods[0].Annotations, C.DeepEquals,
+		[]*DBusAnnotation{{Name: "org.freedesktop.DBus.Method.NoReply", Value: "true"}})
+	c.Check(object.signals[0].Annotations, C.HasLen, 1)
+	c.Check(object.properties[0].Emit, C.Equals, EmitInvalidates)
+	c.Check(object.properties[0].Annotations, C.DeepEquals,
+		[]*DBusAnnotation{{Name: "org.qtproject.QtDBus.QtTypeName", Value: "QString"}})
+
+	// model keeps annotations
+	objects = NewModel(objects).DBusObjects()
+	var buf bytes.Buffer
+	c.Assert(WriteXml(&buf, objects[0]), C.IsNil)
+	written, err := ParseXml(&buf, "com.deepin.Test", "session", "")
+	c.Assert(err, C.IsNil)
+	c.Assert(written, C.HasLen, 1)
+	c.Check(written[0].IntrospectInterface(), C.DeepEquals, object.IntrospectInterface())
+	c.Check(written[0].IntrospectInterface().Properties[0].Annotations, C.HasLen, 2)
+}